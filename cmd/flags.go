@@ -4,47 +4,142 @@ import (
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/iqlusioninc/relayer/relayer"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	flagHash        = "hash"
-	flagURL         = "url"
-	flagForce       = "force"
-	flagFlags       = "flags"
-	flagTimeout     = "timeout"
-	flagConfig      = "config"
-	flagJSON        = "json"
-	flagYAML        = "yaml"
-	flagFile        = "file"
-	flagPath        = "path"
-	flagListenAddr  = "listen"
-	flagTx          = "no-tx"
-	flagBlock       = "no-block"
-	flagData        = "data"
-	flagOrder       = "unordered"
-	flagGas         = "gas"
-	flagGasPrice    = "gas-price"
-	flagMetricsPort = "metrics-port"
-	flagDelay       = "delay"
-	flagGenOnly     = "gen-only"
-	flagRelay       = "relay"
-	flagMaxTxSize    = "max-tx-size"
-	flagMaxMsgLength = "max-msgs"
+	flagHash          = "hash"
+	flagURL           = "url"
+	flagForce         = "force"
+	flagFlags         = "flags"
+	flagTimeout       = "timeout"
+	flagConfig        = "config"
+	flagJSON          = "json"
+	flagYAML          = "yaml"
+	flagFile          = "file"
+	flagPath          = "path"
+	flagListenAddr    = "listen"
+	flagTx            = "no-tx"
+	flagBlock         = "no-block"
+	flagData          = "data"
+	flagOrder         = "unordered"
+	flagGas           = "gas"
+	flagGasPrice      = "gas-price"
+	flagMetricsPort   = "metrics-port"
+	flagControlPort   = "control-port"
+	flagDelay         = "delay"
+	flagGenOnly       = "generate-only"
+	flagGenOnlyOld    = "gen-only"
+	flagOutputDir     = "output-dir"
+	flagMultisig      = "multisig"
+	flagSignatures    = "signatures"
+	flagOffline       = "offline"
+	flagAccountNumber = "account-number"
+	flagSequence      = "sequence"
+	flagDryRun        = "dry-run"
+	flagReuse         = "reuse"
+	flagScenario      = "scenario"
+	flagReport        = "report"
+	flagMaxTxSize     = "max-tx-size"
+	flagMaxMsgLength  = "max-msgs"
+	flagStrategyOpts  = "strategy-opt"
+	flagAll           = "all"
+	flagSweepInterval = "sweep-interval"
 )
 
 func genOnlyFlag(cmd *cobra.Command) *cobra.Command {
-	cmd.Flags().BoolP(flagGenOnly, "", false, "--gen-only")
+	cmd.Flags().BoolP(flagGenOnly, "", false, "write the txs out unsigned, to be signed with 'rly tx sign', instead of broadcasting them")
+	cmd.Flags().StringP(flagOutputDir, "", ".", "directory to write the unsigned txs to")
 	if err := viper.BindPFlag(flagGenOnly, cmd.Flags().Lookup(flagGenOnly)); err != nil {
 		panic(err)
 	}
+	if err := viper.BindPFlag(flagOutputDir, cmd.Flags().Lookup(flagOutputDir)); err != nil {
+		panic(err)
+	}
+	// the flag's old name keeps working for existing invocations
+	cmd.Flags().BoolP(flagGenOnlyOld, "", false, "write the txs out unsigned")
+	if err := cmd.Flags().MarkDeprecated(flagGenOnlyOld, "use --"+flagGenOnly+" instead"); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func reuseFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().BoolP(flagReuse, "", false, "reuse active clients, and open connections and channels over them, that already exist between the chains instead of those of the path")
+	if err := viper.BindPFlag(flagReuse, cmd.Flags().Lookup(flagReuse)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func dryRunFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().BoolP(flagDryRun, "", false, "simulate the txs against the nodes and print their gas and events instead of broadcasting them")
+	if err := viper.BindPFlag(flagDryRun, cmd.Flags().Lookup(flagDryRun)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func multisigFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().StringP(flagMultisig, "", "", "address of the multisig account to sign on behalf of, prints only the signature")
+	cmd.Flags().StringSliceP(flagSignatures, "", []string{}, "signature files of the members of the multisig key to combine")
+	if err := viper.BindPFlag(flagMultisig, cmd.Flags().Lookup(flagMultisig)); err != nil {
+		panic(err)
+	}
+	if err := viper.BindPFlag(flagSignatures, cmd.Flags().Lookup(flagSignatures)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func offlineFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().BoolP(flagOffline, "", false, "sign without querying the chain, using the given account number and sequence")
+	cmd.Flags().Uint64P(flagAccountNumber, "", 0, "account number of the signer, required with --offline")
+	cmd.Flags().Uint64P(flagSequence, "", 0, "sequence of the signer, required with --offline")
+	if err := viper.BindPFlag(flagOffline, cmd.Flags().Lookup(flagOffline)); err != nil {
+		panic(err)
+	}
+	if err := viper.BindPFlag(flagAccountNumber, cmd.Flags().Lookup(flagAccountNumber)); err != nil {
+		panic(err)
+	}
+	if err := viper.BindPFlag(flagSequence, cmd.Flags().Lookup(flagSequence)); err != nil {
+		panic(err)
+	}
 	return cmd
 }
 
-func relayFlag(cmd *cobra.Command) *cobra.Command {
-	cmd.Flags().BoolP(flagRelay, "", false, "--relay")
-	if err := viper.BindPFlag(flagRelay, cmd.Flags().Lookup(flagRelay)); err != nil {
+func allFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().BoolP(flagAll, "a", false, "run over all configured paths")
+	if err := viper.BindPFlag(flagAll, cmd.Flags().Lookup(flagAll)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func sweepIntervalFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().StringP(flagSweepInterval, "", "", "how often to relay any backlog missed by event driven relaying, e.g. 30s (default: the path's sweep-interval)")
+	if err := viper.BindPFlag(flagSweepInterval, cmd.Flags().Lookup(flagSweepInterval)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func scenarioFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().StringP(flagScenario, "", "", "YAML file describing the phases of the load test")
+	if err := viper.BindPFlag(flagScenario, cmd.Flags().Lookup(flagScenario)); err != nil {
+		panic(err)
+	}
+	if err := cmd.MarkFlagRequired(flagScenario); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func reportFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().StringP(flagReport, "", "", "file to write the per packet report to, as CSV if it ends in .csv and as JSON otherwise")
+	if err := viper.BindPFlag(flagReport, cmd.Flags().Lookup(flagReport)); err != nil {
 		panic(err)
 	}
 	return cmd
@@ -180,6 +275,27 @@ func flagsFlag(cmd *cobra.Command) *cobra.Command {
 	return cmd
 }
 
+// setGenOnly sets the chains to write their txs out unsigned if the generate-only flag is set
+func setGenOnly(cmd *cobra.Command, chains ...*relayer.Chain) error {
+	genOnly, err := cmd.Flags().GetBool(flagGenOnly)
+	if err != nil {
+		return err
+	}
+	genOnlyOld, err := cmd.Flags().GetBool(flagGenOnlyOld)
+	if err != nil {
+		return err
+	}
+	genOnly = genOnly || genOnlyOld
+	dir, err := cmd.Flags().GetString(flagOutputDir)
+	if err != nil {
+		return err
+	}
+	for _, c := range chains {
+		c.GenOnly, c.GenOnlyDir = genOnly, dir
+	}
+	return nil
+}
+
 func getTimeout(cmd *cobra.Command) (time.Duration, error) {
 	to, err := cmd.Flags().GetString(flagTimeout)
 	if err != nil {
@@ -228,15 +344,27 @@ func metricsPortFlag(cmd *cobra.Command) *cobra.Command {
 	return cmd
 }
 
+func controlPortFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagControlPort, "", "port to serve the path controls on")
+	if err := viper.BindPFlag(flagControlPort, cmd.Flags().Lookup(flagControlPort)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
 func strategyFlag(cmd *cobra.Command) *cobra.Command {
 	cmd.Flags().StringP(flagMaxTxSize, "s", "2", "maximum size (in MB) of the messages in a relay transaction")
 	cmd.Flags().StringP(flagMaxMsgLength, "l", "5", "maximum number of messages in a relay transaction")
+	cmd.Flags().StringToString(flagStrategyOpts, map[string]string{}, "strategy specific options: --strategy-opt key=value")
 	if err := viper.BindPFlag(flagMaxTxSize, cmd.Flags().Lookup(flagMaxTxSize)); err != nil {
 		panic(err)
 	}
 	if err := viper.BindPFlag(flagMaxMsgLength, cmd.Flags().Lookup(flagMaxMsgLength)); err != nil {
 		panic(err)
 	}
+	if err := viper.BindPFlag(flagStrategyOpts, cmd.Flags().Lookup(flagStrategyOpts)); err != nil {
+		panic(err)
+	}
 	return cmd
 }
 
@@ -1,7 +1,7 @@
 package cmd
 
 import (
-	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/iqlusioninc/relayer/relayer"
 	"github.com/spf13/cobra"
 	"strconv"
 	"time"
@@ -9,11 +9,34 @@ import (
 
 func gunCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "gun [src-chain-id] [dst-chain-id] [amount] [source] [dst-chain-addr] [msgs-count] [[repeats]]",
+		Use:     "gun [src-chain-id] [dst-chain-id]",
 		Aliases: []string{"g"},
-		Short:   "transfer tokens from a source chain to a destination chain in one command",
-		Long:    "This sends tokens from a relayers configured wallet on chain src to a dst addr on dst",
-		Args:    cobra.RangeArgs(6, 7),
+		Short:   "run a load test of transfers between two chains described by a scenario file",
+		Long: `This sends transfers from the relayer's configured wallets between src and dst in the phases of
+the --scenario file, e.g. a ramp-up, a soak and a spike:
+
+seed: 42                # optional, makes the amounts and denoms repeatable
+settle: 30s             # optional, time to wait for the last packets to be received and acked
+report: gun.csv         # optional, per packet report as CSV or JSON, or pass --report
+src-receiver: cosmos1...  # optional, defaults to the key of each chain
+dst-receiver: cosmos1...
+phases:
+- name: ramp-up
+  tps: 5                # or msgs-per-block: 50
+  duration: 1m          # or repeats: 10, counted in rounds of a second or a block, not with open-loop
+  direction: src-dst    # src-dst, dst-src or both
+  relay: true           # not with open-loop
+  open-loop: false      # send at tps without waiting for txs to commit, over the chain's keys
+  burst: 5              # msgs the open-loop token bucket holds, defaults to a second's worth
+  amounts:
+  - {min: 1, max: 10, weight: 3}
+  - {min: 100, max: 1000}
+  denoms:
+  - {denom: stake, source: true}
+
+With --metrics-port the msgs sent, the txs accepted and rejected by code, the backlog of msgs
+waiting to be committed and the broadcast latency of each chain and channel are served at /metrics.`,
+		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			src, dst := args[0], args[1]
 			c, err := config.Chains.Gets(src, dst)
@@ -35,47 +58,57 @@ func gunCmd() *cobra.Command {
 				return err
 			}
 
-			amount, err := sdk.ParseCoin(args[2])
+			file, err := cmd.Flags().GetString(flagScenario)
 			if err != nil {
 				return err
 			}
 
-			source, err := strconv.ParseBool(args[3])
+			scenario, err := relayer.ReadGunScenario(file)
 			if err != nil {
 				return err
 			}
 
-			msgsCount, err := strconv.Atoi(args[5])
+			report, err := cmd.Flags().GetString(flagReport)
 			if err != nil {
 				return err
 			}
-
-			dstAddr, err := sdk.AccAddressFromBech32(args[4])
-			if err != nil {
-				return err
+			if report != "" {
+				scenario.Report = report
 			}
 
 			c[src].NewGas = gas
 			c[dst].NewGas = gas
 
-			relay, err := cmd.Flags().GetBool(flagRelay)
+			dryRun, err := cmd.Flags().GetBool(flagDryRun)
 			if err != nil {
 				return err
 			}
+			c[src].DryRun = dryRun
+			c[dst].DryRun = dryRun
 
-			repeats := 0
-			if len(args) == 7 {
-				repeats, err = strconv.Atoi(args[6])
-				if err != nil {
+			if err = setGenOnly(cmd, c[src], c[dst]); err != nil {
+				return err
+			}
+
+			metricsPort, err := cmd.Flags().GetString(flagMetricsPort)
+			if err != nil {
+				return err
+			}
+			if metricsPort != "" {
+				if err = c[src].ServeMetrics(metricsPort); err != nil {
 					return err
 				}
 			}
 
-			return c[src].Gun(c[dst], amount, dstAddr, source, msgsCount, repeats, relay)
+			return c[src].Gun(c[dst], scenario)
 		},
 	}
 	cmd = pathFlag(cmd)
-	cmd = relayFlag(cmd)
+	cmd = dryRunFlag(cmd)
+	cmd = genOnlyFlag(cmd)
+	cmd = scenarioFlag(cmd)
+	cmd = reportFlag(cmd)
+	cmd = metricsPortFlag(cmd)
 	return gasFlag(cmd)
 }
 
@@ -139,12 +172,9 @@ func slowGunCmd() *cobra.Command {
 				}
 			}
 
-			genOnly, err := cmd.Flags().GetBool(flagGenOnly)
-			if err != nil {
+			if err = setGenOnly(cmd, c[src], c[dst]); err != nil {
 				return err
 			}
-			c[src].GenOnly = genOnly
-			c[dst].GenOnly = genOnly
 
 			delayString, err := cmd.Flags().GetString(flagDelay)
 			if err != nil {
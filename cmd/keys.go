@@ -16,12 +16,16 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
 	"strconv"
 
 	ckeys "github.com/cosmos/cosmos-sdk/client/keys"
 	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	"github.com/spf13/cobra"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/multisig"
 
 	"github.com/iqlusioninc/relayer/relayer"
 )
@@ -41,6 +45,7 @@ func keysCmd() *cobra.Command {
 	cmd.AddCommand(keysShowCmd())
 	cmd.AddCommand(keysExportCmd())
 	cmd.AddCommand(keysGenCmd())
+	cmd.AddCommand(keysMultisigCmd())
 
 	return cmd
 }
@@ -209,6 +214,59 @@ func keysListCmd() *cobra.Command {
 	return cmd
 }
 
+// keysMultisigCmd represents the `keys multisig` command
+func keysMultisigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "multisig [chain-id] [name] [threshold] [key-name...]",
+		Short: "adds a multisig key made of keys in the keychain associated with a particular chain",
+		Long: `This adds a multisig key that needs threshold of the given keys to sign, for signing txs
+with 'rly tx sign'. The keys are sorted by address, as the chain's CLI does by default.`,
+		Args: cobra.MinimumNArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, err := config.Chains.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			done := chain.UseSDKContext()
+			defer done()
+
+			if chain.KeyExists(args[1]) {
+				return errKeyExists(args[1])
+			}
+
+			threshold, err := strconv.Atoi(args[2])
+			if err != nil {
+				return err
+			}
+			if threshold <= 0 || threshold > len(args[3:]) {
+				return fmt.Errorf("threshold must be between 1 and the number of keys, %d", len(args[3:]))
+			}
+
+			pubKeys := make([]crypto.PubKey, 0, len(args[3:]))
+			for _, name := range args[3:] {
+				info, err := chain.Keybase.Key(name)
+				if err != nil {
+					return errKeyDoesntExist(name)
+				}
+				pubKeys = append(pubKeys, info.GetPubKey())
+			}
+			sort.Slice(pubKeys, func(i, j int) bool {
+				return bytes.Compare(pubKeys[i].Address(), pubKeys[j].Address()) < 0
+			})
+
+			info, err := chain.Keybase.SaveMultisig(args[1], multisig.NewPubKeyMultisigThreshold(threshold, pubKeys))
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(info.GetAddress().String())
+			return nil
+		},
+	}
+	return cmd
+}
+
 // keysShowCmd respresents the `keys show` command
 func keysShowCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -3,13 +3,9 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/tendermint/tendermint/types/time"
 	"io/ioutil"
 	"os"
 
-	connTypes "github.com/cosmos/cosmos-sdk/x/ibc/03-connection/types"
-	chanTypes "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/types"
-	tmclient "github.com/cosmos/cosmos-sdk/x/ibc/07-tendermint/types"
 	ibcTypes "github.com/cosmos/cosmos-sdk/x/ibc/types"
 	"github.com/iqlusioninc/relayer/relayer"
 	"github.com/spf13/cobra"
@@ -111,185 +107,29 @@ func pathsGenCmd() *cobra.Command {
 				return overWriteConfig(cmd, config)
 			}
 
-			srcClients, err := c[src].QueryClients(1, 1000)
-			if err != nil {
+			if err = path.ReuseIdentifiers(c[src], c[dst]); err != nil {
 				return err
 			}
-
-			for _, c := range srcClients {
-				// TODO: support other client types through a switch here as they become available
-				clnt, ok := c.(tmclient.ClientState)
-				if ok && clnt.LastHeader.Commit != nil && clnt.LastHeader.Header != nil {
-					if clnt.GetChainID() == dst && !clnt.IsFrozen() &&
-						time.Now().Sub(clnt.GetLatestTimestamp()) < clnt.TrustingPeriod{
-						path.Src.ClientID = clnt.GetID()
-					}
-				}
-			}
-
-			dstClients, err := c[dst].QueryClients(1, 1000)
-			if err != nil {
+			genIdentifiers(path.Src)
+			genIdentifiers(path.Dst)
+			if err = config.Paths.Add(args[4], path); err != nil {
 				return err
 			}
-
-			for _, c := range dstClients {
-				// TODO: support other client types through a switch here as they become available
-				clnt, ok := c.(tmclient.ClientState)
-				if ok && clnt.LastHeader.Commit != nil && clnt.LastHeader.Header != nil {
-					if clnt.GetChainID() == src && !clnt.IsFrozen() &&
-						time.Now().Sub(clnt.GetLatestTimestamp()) < clnt.TrustingPeriod {
-						path.Dst.ClientID = clnt.GetID()
-					}
-				}
-			}
-
-			switch {
-			case path.Src.ClientID == "" && path.Dst.ClientID == "":
-				path.Src.ClientID = relayer.RandLowerCaseLetterString(10)
-				path.Dst.ClientID = relayer.RandLowerCaseLetterString(10)
-				path.Src.ConnectionID = relayer.RandLowerCaseLetterString(10)
-				path.Dst.ConnectionID = relayer.RandLowerCaseLetterString(10)
-				path.Src.ChannelID = relayer.RandLowerCaseLetterString(10)
-				path.Dst.ChannelID = relayer.RandLowerCaseLetterString(10)
-				if err = config.Paths.Add(args[4], path); err != nil {
-					return err
-				}
-				return overWriteConfig(cmd, config)
-			case path.Src.ClientID == "" && path.Dst.ClientID != "":
-				path.Src.ClientID = relayer.RandLowerCaseLetterString(10)
-				path.Src.ConnectionID = relayer.RandLowerCaseLetterString(10)
-				path.Dst.ConnectionID = relayer.RandLowerCaseLetterString(10)
-				path.Src.ChannelID = relayer.RandLowerCaseLetterString(10)
-				path.Dst.ChannelID = relayer.RandLowerCaseLetterString(10)
-				if err = config.Paths.Add(args[4], path); err != nil {
-					return err
-				}
-				return overWriteConfig(cmd, config)
-			case path.Dst.ClientID == "" && path.Src.ClientID != "":
-				path.Dst.ClientID = relayer.RandLowerCaseLetterString(10)
-				path.Src.ConnectionID = relayer.RandLowerCaseLetterString(10)
-				path.Dst.ConnectionID = relayer.RandLowerCaseLetterString(10)
-				path.Src.ChannelID = relayer.RandLowerCaseLetterString(10)
-				path.Dst.ChannelID = relayer.RandLowerCaseLetterString(10)
-				if err = config.Paths.Add(args[4], path); err != nil {
-					return err
-				}
-				return overWriteConfig(cmd, config)
-			}
-
-			srcConns, err := c[src].QueryConnections(1, 1000)
-			if err != nil {
-				return err
-			}
-
-			var srcCon connTypes.ConnectionEnd
-			for _, c := range srcConns {
-				if c.ClientID == path.Src.ClientID {
-					srcCon = c
-					path.Src.ConnectionID = c.ID
-				}
-			}
-
-			dstConns, err := c[dst].QueryConnections(1, 1000)
-			if err != nil {
-				return err
-			}
-
-			var dstCon connTypes.ConnectionEnd
-			for _, c := range dstConns {
-				if c.ClientID == path.Dst.ClientID {
-					dstCon = c
-					path.Dst.ConnectionID = c.ID
-				}
-			}
-
-			switch {
-			case path.Src.ConnectionID != "" && path.Dst.ConnectionID != "":
-				// If we have identified a connection, make sure that each end is the
-				// other's counterparty and that the connection is open. In the failure case
-				// we should generate a new connection identifier
-				dstCpForSrc := srcCon.Counterparty.ConnectionID == dstCon.ID
-				srcCpForDst := dstCon.Counterparty.ConnectionID == srcCon.ID
-				srcOpen := srcCon.State.String() == "OPEN"
-				dstOpen := dstCon.State.String() == "OPEN"
-				if !(dstCpForSrc && srcCpForDst && srcOpen && dstOpen) {
-					path.Src.ConnectionID = relayer.RandLowerCaseLetterString(10)
-					path.Dst.ConnectionID = relayer.RandLowerCaseLetterString(10)
-					path.Src.ChannelID = relayer.RandLowerCaseLetterString(10)
-					path.Dst.ChannelID = relayer.RandLowerCaseLetterString(10)
-					if err = config.Paths.Add(args[4], path); err != nil {
-						return err
-					}
-					return overWriteConfig(cmd, config)
-				}
-			default:
-				path.Src.ConnectionID = relayer.RandLowerCaseLetterString(10)
-				path.Dst.ConnectionID = relayer.RandLowerCaseLetterString(10)
-				path.Src.ChannelID = relayer.RandLowerCaseLetterString(10)
-				path.Dst.ChannelID = relayer.RandLowerCaseLetterString(10)
-				if err = config.Paths.Add(args[4], path); err != nil {
-					return err
-				}
-				return overWriteConfig(cmd, config)
-			}
-
-			srcChans, err := c[src].QueryChannels(1, 1000)
-			if err != nil {
-				return err
-			}
-
-			var srcChan chanTypes.IdentifiedChannel
-			for _, c := range srcChans {
-				if c.ConnectionHops[0] == path.Src.ConnectionID {
-					srcChan = c
-					path.Src.ChannelID = c.ID
-				}
-			}
-
-			dstChans, err := c[dst].QueryChannels(1, 1000)
-			if err != nil {
-				return err
-			}
-
-			var dstChan chanTypes.IdentifiedChannel
-			for _, c := range dstChans {
-				if c.ConnectionHops[0] == path.Dst.ConnectionID {
-					dstChan = c
-					path.Dst.ChannelID = c.ID
-				}
-			}
-
-			switch {
-			case path.Src.ChannelID != "" && path.Dst.ChannelID != "":
-				dstCpForSrc := srcChan.Counterparty.ChannelID == dstChan.ID
-				srcCpForDst := dstChan.Counterparty.ChannelID == srcChan.ID
-				srcOpen := srcChan.State.String() == "OPEN"
-				dstOpen := dstChan.State.String() == "OPEN"
-				srcPort := srcChan.PortID == path.Src.PortID
-				dstPort := dstChan.PortID == path.Dst.PortID
-				srcOrder := srcChan.Ordering.String() == path.Src.Order
-				dstOrder := dstChan.Ordering.String() == path.Dst.Order
-				if !(dstCpForSrc && srcCpForDst && srcOpen && dstOpen && srcPort && dstPort && srcOrder && dstOrder) {
-					path.Src.ChannelID = relayer.RandLowerCaseLetterString(10)
-					path.Dst.ChannelID = relayer.RandLowerCaseLetterString(10)
-				}
-				if err = config.Paths.Add(args[4], path); err != nil {
-					return err
-				}
-				return overWriteConfig(cmd, config)
-			default:
-				path.Src.ChannelID = relayer.RandLowerCaseLetterString(10)
-				path.Dst.ChannelID = relayer.RandLowerCaseLetterString(10)
-				if err = config.Paths.Add(args[4], path); err != nil {
-					return err
-				}
-				return overWriteConfig(cmd, config)
-			}
+			return overWriteConfig(cmd, config)
 		},
 	}
 	return forceFlag(orderFlag(cmd))
 }
 
+// genIdentifiers generates random identifiers for those that aren't set on the path end
+func genIdentifiers(pe *relayer.PathEnd) {
+	for _, id := range []*string{&pe.ClientID, &pe.ConnectionID, &pe.ChannelID} {
+		if *id == "" {
+			*id = relayer.RandLowerCaseLetterString(10)
+		}
+	}
+}
+
 func pathsDeleteCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "delete [index]",
@@ -689,7 +689,12 @@ func queryUnrelayed() *cobra.Command {
 				return err
 			}
 
-			sp, err := relayer.UnrelayedSequences(c[src], c[dst], sh)
+			var sp *relayer.RelaySequences
+			if path.Ordered() {
+				sp, err = relayer.UnrelayedSequences(c[src], c[dst], sh)
+			} else {
+				sp, err = relayer.UnrelayedSequencesUnordered(c[src], c[dst], sh)
+			}
 			if err != nil {
 				return err
 			}
@@ -38,6 +38,11 @@ func rawTransactionCmd() *cobra.Command {
 		xfersend(),
 	)
 
+	for _, sub := range cmd.Commands() {
+		dryRunFlag(sub)
+		genOnlyFlag(sub)
+	}
+
 	return cmd
 }
 func updateClientCmd() *cobra.Command {
@@ -96,13 +101,6 @@ func updateClientCmd() *cobra.Command {
 				chains[dst].NewGasPrices = gasPrices[1]
 			}
 
-			genOnly, err := cmd.Flags().GetBool(flagGenOnly)
-			if err != nil {
-				return err
-			}
-			chains[src].GenOnly = genOnly
-			chains[dst].GenOnly = genOnly
-
 			delayString, err := cmd.Flags().GetString(flagDelay)
 			if err != nil {
 				return err
@@ -125,7 +123,6 @@ func updateClientCmd() *cobra.Command {
 	cmd = gasFlag(cmd)
 	cmd = gasPriceFlag(cmd)
 	cmd = delayFlag(cmd)
-	cmd = genOnlyFlag(cmd)
 	return heightFlag(cmd)
 }
 
@@ -676,6 +673,12 @@ func closeChannelStepCmd() *cobra.Command {
 	return cmd
 }
 
-func sendAndPrint(txs []sdk.Msg, c *relayer.Chain, cmd *cobra.Command) error {
+func sendAndPrint(txs []sdk.Msg, c *relayer.Chain, cmd *cobra.Command) (err error) {
+	if c.DryRun, err = cmd.Flags().GetBool(flagDryRun); err != nil {
+		return err
+	}
+	if err = setGenOnly(cmd, c); err != nil {
+		return err
+	}
 	return c.SendAndPrint(txs, false, false)
 }
@@ -28,10 +28,6 @@ import (
 	"github.com/spf13/viper"
 )
 
-const (
-	MB = 1048576 // in bytes
-)
-
 var (
 	cfgPath     string
 	homePath    string
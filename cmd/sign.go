@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+
+	keys "github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/spf13/cobra"
+
+	"github.com/iqlusioninc/relayer/relayer"
+)
+
+func signTxCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign [chain-id] [tx-file] [key-name]",
+		Short: "sign a tx written out with --generate-only",
+		Long: `This signs the unsigned tx in tx-file with a key from the chain's keyring and prints it.
+With --multisig the key signs on behalf of the multisig account and only its signature is
+printed. If the key is a multisig key, the signatures of its members passed with --signatures
+are combined instead. With --offline the chain isn't queried for the signer's account number
+and sequence, which are printed when the tx is generated.`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, err := config.Chains.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			tx, err := chain.ReadStdTx(args[1])
+			if err != nil {
+				return err
+			}
+
+			info, err := chain.Keybase.Key(args[2])
+			if err != nil {
+				return err
+			}
+
+			multisig, err := cmd.Flags().GetString(flagMultisig)
+			if err != nil {
+				return err
+			}
+
+			signer := info.GetAddress()
+			if multisig != "" {
+				done := chain.UseSDKContext()
+				signer, err = sdk.AccAddressFromBech32(multisig)
+				done()
+				if err != nil {
+					return err
+				}
+			}
+
+			accNum, seq, err := signerAccount(cmd, chain, signer)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case multisig != "":
+				sig, err := chain.SignTxForMultisig(tx, args[2], signer, accNum, seq)
+				if err != nil {
+					return err
+				}
+				return chain.Print(sig, false, true)
+
+			case info.GetType() == keys.TypeMulti:
+				files, err := cmd.Flags().GetStringSlice(flagSignatures)
+				if err != nil {
+					return err
+				}
+				if len(files) == 0 {
+					return fmt.Errorf("%s is a multisig key, pass the signatures of its members with --%s", args[2], flagSignatures)
+				}
+
+				sigs := make([]auth.StdSignature, len(files))
+				for i, file := range files {
+					if sigs[i], err = chain.ReadStdSignature(file); err != nil {
+						return err
+					}
+				}
+
+				if tx, err = chain.MultisignTx(tx, args[2], sigs, accNum, seq); err != nil {
+					return err
+				}
+				return chain.Print(tx, false, true)
+
+			default:
+				if tx, err = chain.SignTx(tx, args[2], accNum, seq); err != nil {
+					return err
+				}
+				return chain.Print(tx, false, true)
+			}
+		},
+	}
+	cmd = multisigFlag(cmd)
+	return offlineFlag(cmd)
+}
+
+// signerAccount returns the account number and sequence to sign with, from the flags
+// if signing offline and otherwise from the chain
+func signerAccount(cmd *cobra.Command, chain *relayer.Chain, signer sdk.AccAddress) (uint64, uint64, error) {
+	offline, err := cmd.Flags().GetBool(flagOffline)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !offline {
+		return chain.SignerAccount(signer)
+	}
+
+	if !cmd.Flags().Changed(flagAccountNumber) || !cmd.Flags().Changed(flagSequence) {
+		return 0, 0, fmt.Errorf("--%s and --%s are required with --%s", flagAccountNumber, flagSequence, flagOffline)
+	}
+
+	accNum, err := cmd.Flags().GetUint64(flagAccountNumber)
+	if err != nil {
+		return 0, 0, err
+	}
+	seq, err := cmd.Flags().GetUint64(flagSequence)
+	return accNum, seq, err
+}
+
+func broadcastTxCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "broadcast [chain-id] [tx-file]",
+		Short: "broadcast a tx signed with 'rly tx sign'",
+		Long:  "This broadcasts the signed tx in tx-file to the chain and waits for it to be included in a block",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, err := config.Chains.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			tx, err := chain.ReadStdTx(args[1])
+			if err != nil {
+				return err
+			}
+
+			if len(tx.Signatures) == 0 {
+				return fmt.Errorf("tx in %s isn't signed", args[1])
+			}
+
+			res, err := chain.BroadcastSignedTx(tx)
+			if err != nil {
+				return err
+			}
+			return chain.Print(res, false, true)
+		},
+	}
+	return cmd
+}
@@ -19,6 +19,8 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"syscall"
 
 	"github.com/iqlusioninc/relayer/relayer"
@@ -29,23 +31,64 @@ import (
 // NOTE: This is basically psuedocode
 func startCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "start [path-name]",
+		Use:     "start [path-name]...",
 		Aliases: []string{"st"},
-		Short:   "Start the listening relayer on a given path",
-		Args:    cobra.ExactArgs(1),
+		Short:   "Start the listening relayer on one or more paths",
+		Long: strings.TrimSpace(`Start the listening relayer on the given paths, or on every configured path with --all.
+When relaying over more than one path, each chain is subscribed to once and its events are shared by all of the paths that use it.
+With --metrics-port the gas price each chain's txs are signed with is served at /metrics.
+With --control-port a GET of /paths lists the state of each path, and a POST to /paths/{name}/stop, /start or /restart
+stops, starts or restarts that path without affecting the others.`),
+		Args: cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, src, dst, err := config.ChainsFromPath(args[0])
+			all, err := cmd.Flags().GetBool(flagAll)
 			if err != nil {
 				return err
 			}
 
-			path := config.Paths.MustGet(args[0])
-			strategy, err := GetStrategyWithOptions(cmd, path.MustGetStrategy())
+			names := args
+			if all {
+				names = make([]string, 0, len(config.Paths))
+				for name := range config.Paths {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+			}
+
+			if len(names) == 0 {
+				return fmt.Errorf("must pass at least one path name or --all")
+			}
+
+			metricsPort, err := cmd.Flags().GetString(flagMetricsPort)
+			if err != nil {
+				return err
+			}
+			if metricsPort != "" {
+				// any chain can serve the metrics, they are shared by the process
+				path, err := config.Paths.Get(names[0])
+				if err != nil {
+					return err
+				}
+				c, err := config.Chains.Get(path.Src.ChainID)
+				if err != nil {
+					return err
+				}
+				if err = c.ServeMetrics(metricsPort); err != nil {
+					return err
+				}
+			}
+
+			controlPort, err := cmd.Flags().GetString(flagControlPort)
 			if err != nil {
 				return err
 			}
 
-			done, err := relayer.RunStrategy(c[src], c[dst], strategy, path.Ordered())
+			var done func()
+			if len(names) == 1 && controlPort == "" {
+				done, err = startPath(cmd, names[0])
+			} else {
+				done, err = startPaths(cmd, names, controlPort)
+			}
 			if err != nil {
 				return err
 			}
@@ -54,9 +97,99 @@ func startCmd() *cobra.Command {
 			return nil
 		},
 	}
+	cmd = allFlag(cmd)
+	cmd = sweepIntervalFlag(cmd)
+	cmd = metricsPortFlag(cmd)
+	cmd = controlPortFlag(cmd)
 	return strategyFlag(cmd)
 }
 
+// startPath runs the relayer over a single path
+func startPath(cmd *cobra.Command, name string) (func(), error) {
+	c, src, dst, err := config.ChainsFromPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	path := config.Paths.MustGet(name)
+	strategy, err := GetStrategyWithOptions(cmd, path)
+	if err != nil {
+		return nil, err
+	}
+
+	sweep, err := GetSweepInterval(cmd, path)
+	if err != nil {
+		return nil, err
+	}
+
+	// the relay state store keeps track of the txs that are broadcast,
+	// so a restarted relayer doesn't submit them again
+	closeStore := useRelayStore(name, c[src], c[dst])
+	done, err := relayer.RunStrategyWithSweep(c[src], c[dst], strategy, path.Ordered(), sweep)
+	if err != nil {
+		closeStore()
+		return nil, err
+	}
+	return func() { done(); closeStore() }, nil
+}
+
+// startPaths runs the relayer over many paths, sharing the chains between them. If controlPort
+// is set the paths can be stopped and restarted through it.
+func startPaths(cmd *cobra.Command, names []string, controlPort string) (done func(), err error) {
+	var closeStores []func()
+	closeAll := func() {
+		for _, closeStore := range closeStores {
+			closeStore()
+		}
+	}
+	defer func() {
+		if err != nil {
+			closeAll()
+		}
+	}()
+
+	mr := relayer.NewMultiPathRelayer()
+	for _, name := range names {
+		path, err := config.Paths.Get(name)
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := config.Chains.Gets(path.Src.ChainID, path.Dst.ChainID)
+		if err != nil {
+			return nil, err
+		}
+
+		strategy, err := GetStrategyWithOptions(cmd, path)
+		if err != nil {
+			return nil, err
+		}
+
+		sweep, err := GetSweepInterval(cmd, path)
+		if err != nil {
+			return nil, err
+		}
+
+		// the path's chains are copied when it is added, so each path keeps its own store
+		closeStores = append(closeStores, useRelayStore(name, c[path.Src.ChainID], c[path.Dst.ChainID]))
+		if err = mr.AddPath(name, path, c[path.Src.ChainID], c[path.Dst.ChainID], strategy, sweep); err != nil {
+			return nil, err
+		}
+	}
+
+	stop, err := mr.Start()
+	if err != nil {
+		return nil, err
+	}
+	if controlPort != "" {
+		if err = mr.ServeControl(controlPort); err != nil {
+			stop()
+			return nil, err
+		}
+	}
+	return func() { stop(); closeAll() }, nil
+}
+
 // trap signal waits for a SIGINT or SIGTERM and then sends down the done channel
 func trapSignal(done func()) {
 	sigCh := make(chan os.Signal, 1)
@@ -1,51 +1,52 @@
 package cmd
 
 import (
-	"fmt"
-	"strconv"
+	"time"
 
 	"github.com/iqlusioninc/relayer/relayer"
 	"github.com/spf13/cobra"
 )
 
-// GetStrategyWithOptions sets strategy specific fields.
-func GetStrategyWithOptions(cmd *cobra.Command, strategy relayer.Strategy) (relayer.Strategy, error) {
-	switch strategy.GetType() {
-	case (&relayer.NaiveStrategy{}).GetType():
-		ns, ok := strategy.(*relayer.NaiveStrategy)
-		if !ok {
-			return strategy, fmt.Errorf("strategy.GetType() returns naive, but strategy type (%T) is not type NaiveStrategy", strategy)
-
-		}
+// GetStrategyWithOptions returns the strategy configured on the path, or the naive strategy if
+// there is none, with any strategy specific options passed on the command line applied. Options
+// set on the command line take precedence over the constraints configured in the path.
+func GetStrategyWithOptions(cmd *cobra.Command, path *relayer.Path) (relayer.Strategy, error) {
+	opts, err := cmd.Flags().GetStringToString(flagStrategyOpts)
+	if err != nil {
+		return nil, err
+	}
 
-		maxTxSize, err := cmd.Flags().GetString(flagMaxTxSize)
-		if err != nil {
-			return ns, err
+	// only pass along the well known flags if the user has set them, so they
+	// don't override the path config or the strategy defaults
+	for _, flag := range []string{flagMaxTxSize, flagMaxMsgLength} {
+		if !cmd.Flags().Changed(flag) {
+			continue
 		}
-
-		txSize, err := strconv.ParseUint(maxTxSize, 10, 64)
+		val, err := cmd.Flags().GetString(flag)
 		if err != nil {
-			return ns, err
-		}
-
-		// set max size of messages in a relay transaction
-		ns.MaxTxSize = txSize * MB // in MB
-
-		maxMsgLength, err := cmd.Flags().GetString(flagMaxMsgLength)
-		if err != nil {
-			return ns, err
+			return nil, err
 		}
+		opts[flag] = val
+	}
 
-		msgLen, err := strconv.ParseUint(maxMsgLength, 10, 64)
-		if err != nil {
-			return ns, err
-		}
+	// paths written by hand may leave out the strategy
+	cfg := path.Strategy
+	if cfg == nil {
+		cfg = relayer.NewNaiveStrategy()
+	}
+	return cfg.WithOptions(opts).GetStrategy()
+}
 
-		// set max length messages in relay transaction
-		ns.MaxMsgLength = msgLen
+// GetSweepInterval returns how often to sweep the backlog of the path, the
+// --sweep-interval flag takes precedence over the interval configured in the path
+func GetSweepInterval(cmd *cobra.Command, path *relayer.Path) (time.Duration, error) {
+	if !cmd.Flags().Changed(flagSweepInterval) {
+		return path.Strategy.GetSweepInterval()
+	}
 
-		return ns, nil
-	default:
-		return strategy, nil
+	interval, err := cmd.Flags().GetString(flagSweepInterval)
+	if err != nil {
+		return 0, err
 	}
+	return (&relayer.StrategyCfg{SweepInterval: interval}).GetSweepInterval()
 }
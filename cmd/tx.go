@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/client/flags"
+	ibctypes "github.com/cosmos/cosmos-sdk/x/ibc/types"
 	"github.com/iqlusioninc/relayer/relayer"
 	"github.com/spf13/cobra"
 )
@@ -50,6 +51,9 @@ func transactionCmd() *cobra.Command {
 		flags.LineBreak,
 		rawTransactionCmd(),
 		sendPacketCmd(),
+		flags.LineBreak,
+		signTxCmd(),
+		broadcastTxCmd(),
 	)
 
 	return cmd
@@ -91,6 +95,7 @@ func createConnectionCmd() *cobra.Command {
 				return err
 			}
 
+			defer useRelayStore(args[0], c[src], c[dst])()
 			return c[src].CreateConnection(c[dst], to)
 		},
 	}
@@ -116,8 +121,8 @@ func createChannelCmd() *cobra.Command {
 				return err
 			}
 
-			// TODO: read order out of path config
-			return c[src].CreateChannel(c[dst], true, to)
+			defer useRelayStore(args[0], c[src], c[dst])()
+			return c[src].CreateChannel(c[dst], config.Paths.MustGet(args[0]).Ordered(), to)
 		},
 	}
 
@@ -173,39 +178,110 @@ func fullPathCmd() *cobra.Command {
 			c[src].Delay = delay
 			c[dst].Delay = delay
 
-			genOnly, err := cmd.Flags().GetBool(flagGenOnly)
+			if err = setGenOnly(cmd, c[src], c[dst]); err != nil {
+				return err
+			}
+
+			dryRun, err := cmd.Flags().GetBool(flagDryRun)
 			if err != nil {
 				return err
 			}
-			c[src].GenOnly = genOnly
-			c[dst].GenOnly = genOnly
+			c[src].DryRun = dryRun
+			c[dst].DryRun = dryRun
 
 			to, err := getTimeout(cmd)
 			if err != nil {
 				return err
 			}
 
+			reuse, err := cmd.Flags().GetBool(flagReuse)
+			if err != nil {
+				return err
+			}
+			if reuse {
+				// the chains share the path's ends, so they use any identifiers that are replaced
+				if err = config.Paths.MustGet(args[0]).ReuseIdentifiers(c[src], c[dst]); err != nil {
+					return err
+				}
+				if !dryRun {
+					if err = overWriteConfig(cmd, config); err != nil {
+						return err
+					}
+				}
+			}
+
+			defer useRelayStore(args[0], c[src], c[dst])()
+
 			if err = c[src].CreateClients(c[dst]); err != nil {
 				return err
 			}
 
+			if dryRun || c[src].GenOnly {
+				return handshakeStep(c[src], c[dst], config.Paths.MustGet(args[0]).Ordered(), to)
+			}
+
 			if err = c[src].CreateConnection(c[dst], to); err != nil {
 				return err
 			}
 
-			return c[src].CreateChannel(c[dst], true, to)
+			return c[src].CreateChannel(c[dst], config.Paths.MustGet(args[0]).Ordered(), to)
 		},
 	}
 	cmd = delayFlag(cmd)
 	cmd = genOnlyFlag(cmd)
+	cmd = dryRunFlag(cmd)
+	cmd = reuseFlag(cmd)
 	return timeoutFlag(cmd)
 }
 
+// useRelayStore opens the relay state store of the path for its chains to record their txs and the
+// progress of their handshakes in, so that an interrupted handshake is resumed by running the command
+// again. The chains still relay if it can't be opened, e.g. while 'rly start' relays the path, and the
+// returned func closes it.
+func useRelayStore(name string, chains ...*relayer.Chain) func() {
+	store, err := relayer.OpenRelayStore(homePath, name)
+	if err != nil {
+		chains[0].Error(fmt.Errorf("txs and handshake progress of path %s won't be recorded: %w", name, err))
+		return func() {}
+	}
+	for _, c := range chains {
+		c.UseRelayStore(store)
+	}
+	return func() { store.Close() }
+}
+
+// handshakeStep sends the next step of the connection handshake between src and dst, or of the
+// channel handshake once the connection is open. It is used when the txs are simulated or
+// generated rather than broadcast, so the handshakes only advance one step per run. Neither
+// can be stepped until both clients exist, because the handshake msgs are built from them.
+func handshakeStep(src, dst *relayer.Chain, ordered bool, to time.Duration) error {
+	for _, c := range []*relayer.Chain{src, dst} {
+		cs, err := c.QueryClientState()
+		if err != nil {
+			return err
+		}
+		if cs == nil {
+			c.Log(fmt.Sprintf("- [%s] client{%s} doesn't exist yet, the handshakes can't be stepped yet",
+				c.ChainID, c.PathEnd.ClientID))
+			return nil
+		}
+	}
+
+	conns, err := relayer.QueryConnectionPair(src, dst, 0, 0)
+	if err != nil {
+		return err
+	}
+	if conns[src.ChainID].Connection.State != ibctypes.OPEN || conns[dst.ChainID].Connection.State != ibctypes.OPEN {
+		return src.CreateConnection(dst, to)
+	}
+	return src.CreateChannel(dst, ordered, to)
+}
+
 func relayMsgsCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "relay [path-name] [[direction]]",
 		Aliases: []string{"rly", "queue"},
-		Short:   "relay any packets that remain to be relayed on a given path, in both directions",
+		Short:   "relay any packets and acknowledgements that remain to be relayed on a given path, in both directions",
 		Args:    cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c, src, dst, err := config.ChainsFromPath(args[0])
@@ -235,10 +311,19 @@ func relayMsgsCmd() *cobra.Command {
 			c[src].NewGas = gas
 			c[dst].NewGas = gas
 
-			direction := ""
-			if args[1] == "" {
-				direction = "both"
-			} else {
+			dryRun, err := cmd.Flags().GetBool(flagDryRun)
+			if err != nil {
+				return err
+			}
+			c[src].DryRun = dryRun
+			c[dst].DryRun = dryRun
+
+			if err = setGenOnly(cmd, c[src], c[dst]); err != nil {
+				return err
+			}
+
+			direction := "both"
+			if len(args) > 1 {
 				direction = args[1]
 			}
 
@@ -247,7 +332,12 @@ func relayMsgsCmd() *cobra.Command {
 				return err
 			}
 
-			sp, err := relayer.UnrelayedSequences(c[src], c[dst], sh)
+			var sp *relayer.RelaySequences
+			if config.Paths.MustGet(args[0]).Ordered() {
+				sp, err = relayer.UnrelayedSequences(c[src], c[dst], sh)
+			} else {
+				sp, err = relayer.UnrelayedSequencesUnordered(c[src], c[dst], sh)
+			}
 			if err != nil {
 				return err
 			}
@@ -256,12 +346,19 @@ func relayMsgsCmd() *cobra.Command {
 				return err
 			}
 
-			return nil
+			ap, err := relayer.UnrelayedAcknowledgements(c[src], c[dst], sh)
+			if err != nil {
+				return err
+			}
+
+			return relayer.RelayAcknowledgements(c[src], c[dst], sh, ap, direction)
 		},
 	}
 
 	cmd = gasFlag(cmd)
 	cmd = gasPriceFlag(cmd)
+	cmd = dryRunFlag(cmd)
+	cmd = genOnlyFlag(cmd)
 	return strategyFlag(cmd)
 }
 
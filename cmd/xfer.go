@@ -51,6 +51,17 @@ func xfersend() *cobra.Command {
 			}
 			done ()
 			
+			dryRun, err := cmd.Flags().GetBool(flagDryRun)
+			if err != nil {
+				return err
+			}
+			c[src].DryRun = dryRun
+			c[dst].DryRun = dryRun
+
+			if err = setGenOnly(cmd, c[src], c[dst]); err != nil {
+				return err
+			}
+
 			return c[src].SendTransferMsg(c[dst], amount, dstAddr, source)
 		},
 	}
@@ -97,9 +108,22 @@ func transferCmd() *cobra.Command {
 			}
 			done()
 
+			dryRun, err := cmd.Flags().GetBool(flagDryRun)
+			if err != nil {
+				return err
+			}
+			c[src].DryRun = dryRun
+			c[dst].DryRun = dryRun
+
+			if err = setGenOnly(cmd, c[src], c[dst]); err != nil {
+				return err
+			}
+
 			return c[src].SendTransferBothSides(c[dst], amount, dstAddr, source)
 		},
 	}
+	cmd = dryRunFlag(cmd)
+	cmd = genOnlyFlag(cmd)
 	return pathFlag(cmd)
 }
 
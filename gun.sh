@@ -2,7 +2,6 @@
 
 SRC_CHAIN_FILE="configs/demo/ibc0.json"
 DST_CHAIN_FILE="configs/demo/ibc1.json"
-AMOUNT="1n0token"
 
 port_id="transfer"
 
@@ -26,6 +25,5 @@ rgun paths gen $src_chain_id $port_id $dst_chain_id $port_id "$src_chain_id-$dst
 
 rgun tx link "$src_chain_id-$dst_chain_id"
 
-#rgun tx gun $src_chain_id $dst_chain_id $AMOUNT true $(rgun ch addr $dst_chain_id) 10 --gas 1000000 -d
-#rgun tx gun $dst_chain_id $src_chain_id $AMOUNT false $(rgun ch addr $src_chain_id) 10 --gas 1000000 -d
+#rgun tx gun $src_chain_id $dst_chain_id --scenario configs/demo/gun.yaml --gas 1000000 -d
 #rgun tx gun_update_client $src_chain_id $dst_chain_id 5s
\ No newline at end of file
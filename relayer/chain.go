@@ -19,7 +19,6 @@ import (
 	codecstd "github.com/cosmos/cosmos-sdk/std"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/auth"
-	authclient "github.com/cosmos/cosmos-sdk/x/auth/client"
 	"github.com/cosmos/go-bip39"
 	"github.com/tendermint/tendermint/libs/log"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
@@ -44,6 +43,11 @@ type Chain struct {
 	NewGas       uint64
 	NewGasPrices string
 
+	// MaxGasPrices enables raising the gas prices, up to these, when txs are rejected for
+	// insufficient fees. The raise decays back toward the gas prices, halving every GasPriceDecay.
+	MaxGasPrices  string `yaml:"max-gas-prices,omitempty" json:"max-gas-prices,omitempty"`
+	GasPriceDecay string `yaml:"gas-price-decay,omitempty" json:"gas-price-decay,omitempty"`
+
 	// TODO: make these private
 	HomePath string                `yaml:"-" json:"-"`
 	PathEnd  *PathEnd              `yaml:"-" json:"-"`
@@ -59,12 +63,77 @@ type Chain struct {
 
 	Delay time.Duration
 
-	GenOnly bool
+	// GenOnly writes the txs the chain would send to GenOnlyDir unsigned instead of signing
+	// and broadcasting them
+	GenOnly    bool   `yaml:"-" json:"-"`
+	GenOnlyDir string `yaml:"-" json:"-"`
+
+	// DryRun simulates the txs the chain would send against its node instead of broadcasting them
+	DryRun bool `yaml:"-" json:"-"`
+
+	// txObserver is called with the outcome of every tx the chain broadcasts and the time it
+	// was first broadcast, if it is set. It isn't guarded, so it is only set or cleared while
+	// no goroutine is sending the chain's txs.
+	txObserver func(res sdk.TxResponse, err error, msgs []sdk.Msg, sentAt time.Time)
 
 	Keys []string `yaml:"keys" json:"keys"`
 
+	// KeySelection is how relay txs are spread over the Keys, either round-robin or least-loaded.
+	// If it isn't set all txs are signed by the Key.
+	KeySelection string `yaml:"key-selection,omitempty" json:"key-selection,omitempty"`
+
+	// BroadcastMode is how relay txs are broadcast, either block, sync or async. With sync and
+	// async the relayer polls for the tx until it is included or ConfirmTimeout expires.
+	BroadcastMode  string `yaml:"broadcast-mode,omitempty" json:"broadcast-mode,omitempty"`
+	ConfirmTimeout string `yaml:"confirm-timeout,omitempty" json:"confirm-timeout,omitempty"`
+
 	// stores facuet addresses that have been used reciently
 	faucetAddrs map[string]time.Time
+
+	// records the relay txs broadcast to the chain, if set
+	store *RelayStore
+
+	// hands out the account sequences of the chain's keys
+	sequences *sequenceTracker
+
+	// selects the key that signs each relay tx
+	signers *signerPool
+
+	// the gas used by each msg type in the chain's txs
+	gasProfiles *gasProfiles
+
+	// raises the gas prices when the chain's min fee is higher
+	gasPricer *gasPricer
+
+	// the chain's RPC client, shared with the copies of the chain made for its paths
+	rpc *rpcConn
+}
+
+// rpcConn guards an RPC client that is replaced when the relayer reconnects to the chain
+type rpcConn struct {
+	mu     sync.RWMutex
+	client rpcclient.Client
+}
+
+// RPCClient returns the chain's current RPC client. Unlike the Client field, which is only
+// updated on the chain Reconnect is called on, it follows reconnects made by any copy of the
+// chain and is safe to call while they happen.
+func (src *Chain) RPCClient() rpcclient.Client {
+	if src.rpc == nil {
+		return src.Client
+	}
+	src.rpc.mu.RLock()
+	defer src.rpc.mu.RUnlock()
+	return src.rpc.client
+}
+
+// swap replaces the client and returns the one it replaced
+func (rc *rpcConn) swap(client rpcclient.Client) rpcclient.Client {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	old := rc.client
+	rc.client = client
+	return old
 }
 
 // ListenRPCEmitJSON listens for tx and block events from a chain and outputs them as JSON to stdout
@@ -149,13 +218,38 @@ func (src *Chain) Init(homePath string, cdc *codecstd.Codec, amino *aminocodec.C
 		return err
 	}
 
+	if _, err = sdk.ParseDecCoins(src.MaxGasPrices); err != nil {
+		return fmt.Errorf("failed to parse max gas prices (%s) for chain %s", src.MaxGasPrices, src.ChainID)
+	}
+
+	if src.GasPriceDecay != "" {
+		if _, err = time.ParseDuration(src.GasPriceDecay); err != nil {
+			return fmt.Errorf("failed to parse gas price decay (%s) for chain %s", src.GasPriceDecay, src.ChainID)
+		}
+	}
+
 	_, err = time.ParseDuration(src.TrustingPeriod)
 	if err != nil {
 		return fmt.Errorf("failed to parse trusting period (%s) for chain %s", src.TrustingPeriod, src.ChainID)
 	}
 
+	if err = validateKeySelection(src.KeySelection); err != nil {
+		return fmt.Errorf("chain %s: %w", src.ChainID, err)
+	}
+
+	if err = validateBroadcastMode(src.BroadcastMode); err != nil {
+		return fmt.Errorf("chain %s: %w", src.ChainID, err)
+	}
+
+	if src.ConfirmTimeout != "" {
+		if _, err = time.ParseDuration(src.ConfirmTimeout); err != nil {
+			return fmt.Errorf("failed to parse confirm timeout (%s) for chain %s", src.ConfirmTimeout, src.ChainID)
+		}
+	}
+
 	src.Keybase = keybase
 	src.Client = client
+	src.rpc = &rpcConn{client: client}
 	src.Cdc = newContextualStdCodec(cdc, src.UseSDKContext)
 	src.Amino = newContextualAminoCodec(amino, src.UseSDKContext)
 	RegisterCodec(amino)
@@ -164,6 +258,10 @@ func (src *Chain) Init(homePath string, cdc *codecstd.Codec, amino *aminocodec.C
 	src.timeout = timeout
 	src.debug = debug
 	src.faucetAddrs = make(map[string]time.Time)
+	src.sequences = newSequenceTracker()
+	src.signers = newSignerPool(src.KeySelection)
+	src.gasProfiles = newGasProfiles()
+	src.gasPricer = &gasPricer{}
 	return nil
 }
 
@@ -213,96 +311,255 @@ func (src *Chain) SendMsg(datagram sdk.Msg) (sdk.TxResponse, error) {
 	return src.SendMsgs([]sdk.Msg{datagram})
 }
 
-// SendMsgs wraps the msgs in a stdtx, signs and sends it
+// SendMsgs wraps the msgs in a stdtx, signs and sends it with the chain's broadcast mode.
+// It returns once the tx has been included in a block, has failed or the confirm timeout expired.
 func (src *Chain) SendMsgs(datagrams []sdk.Msg) (res sdk.TxResponse, err error) {
-	var out []byte
-	if out, err = src.BuildAndSignTx(datagrams); err != nil {
-		return res, err
-	}
-	if src.GenOnly {
-		return sdk.TxResponse{}, nil
-	}
-	return src.BroadcastTxCommit(out)
+	return src.sendMsgs(datagrams, src.broadcastTx, true)
 }
 
+// SendMsgsSync wraps the msgs in a stdtx, signs and sends it, returning once it has passed CheckTx
 func (src *Chain) SendMsgsSync(datagrams []sdk.Msg) (res sdk.TxResponse, err error) {
-	var out []byte
-	if out, err = src.BuildAndSignTx(datagrams); err != nil {
+	return src.sendMsgs(datagrams, src.BroadcastTxSync, false)
+}
+
+// sendMsgs signs the msgs with the next sequence of one of the chain's signers and broadcasts them
+// using the given function, resyncing the sequence if the tx doesn't make it into the mempool.
+// If confirm is set it waits for a tx that was broadcast without being committed to be included.
+func (src *Chain) sendMsgs(datagrams []sdk.Msg, broadcast func([]byte) (sdk.TxResponse, error), confirm bool) (
+	res sdk.TxResponse, err error) {
+	info, msgs, release, err := src.acquireSigner(datagrams)
+	if err != nil {
 		return res, err
 	}
+	defer release()
+	return src.sendMsgsWithKey(msgs, info, broadcast, confirm)
+}
+
+// sendMsgsWithKey signs the msgs with the given key and broadcasts them using the given function.
+// A tx that runs out of gas is resent with a higher gas limit up to outOfGasRetries times, and one
+// rejected for an insufficient fee is resent with higher gas prices up to insufficientFeeRetries times.
+func (src *Chain) sendMsgsWithKey(datagrams []sdk.Msg, info keys.Info, broadcast func([]byte) (sdk.TxResponse, error),
+	confirm bool) (res sdk.TxResponse, err error) {
+	var (
+		gas                    uint64
+		gasRetries, feeRetries int
+	)
 	if src.GenOnly {
-		return sdk.TxResponse{}, nil
+		return src.generateTx(datagrams, info, gas)
+	}
+	sentAt := time.Now()
+	for {
+		var tx signedTx
+		if tx, err = src.buildAndSignTx(datagrams, info, gas); err != nil {
+			return res, err
+		}
+		if src.DryRun {
+			// the sequence isn't used by a tx that is only simulated
+			src.sequences.resync(info.GetAddress())
+			return src.simulateTx(tx.bytes, tx.gas, datagrams)
+		}
+		track := src.trackTx(tx.bytes, info.GetAddress(), tx.sequence, datagrams)
+		res, err = broadcast(tx.bytes)
+		src.sequences.broadcastResult(info.GetAddress(), res, err)
+		if confirm && pendingInclusion(res, err) {
+			hash := txHash(tx.bytes)
+			if res, err = src.WaitForTx(hash); err != nil && src.txDropped(hash) {
+				// the tx was dropped from the mempool, so its sequence wasn't used. One that is
+				// still pending keeps its sequence, or the next tx would be signed with it too.
+				src.sequences.resync(info.GetAddress())
+			}
+		}
+		track(res, err)
+
+		if err == nil && res.Code == 0 && res.Height > 0 {
+			src.gasProfiles.observe(datagrams, uint64(res.GasUsed))
+		}
+
+		switch {
+		case outOfGas(res, err) && gasRetries < outOfGasRetries:
+			gasRetries++
+			gas = src.bumpGas(res, tx.gas)
+		case insufficientFee(res, err) && feeRetries < insufficientFeeRetries && src.raiseGasPrices(res, tx.gas):
+			feeRetries++
+			gas = tx.gas
+		default:
+			if src.txObserver != nil {
+				src.txObserver(res, err, datagrams, sentAt)
+			}
+			if !src.debug {
+				res.RawLog = ""
+			}
+			return res, err
+		}
 	}
-	return src.BroadcastTxSync(out)
 }
 
 // BuildAndSignTx takes messages and builds, signs and marshals a sdk.Tx to prepare it for broadcast
+// NOTE: the tx is signed with the next sequence of the chain's key, which is released again as the
+// tx isn't broadcast by the chain. The next tx the chain signs queries the account's sequence.
 func (src *Chain) BuildAndSignTx(msgs []sdk.Msg) ([]byte, error) {
-	done := src.UseSDKContext()
-	defer done()
-
-	// Fetch account and sequence numbers for the account
-	acc, err := auth.NewAccountRetriever(src.Cdc, src).GetAccount(src.MustGetAddress())
+	info, err := src.Keybase.Key(src.Key)
 	if err != nil {
 		return nil, err
 	}
-
-	gas := src.NewGas
-	if gas == 0 {
-		gas = src.Gas
+	tx, err := src.buildAndSignTx(msgs, info, 0)
+	if err == nil {
+		src.sequences.resync(info.GetAddress())
 	}
+	return tx.bytes, err
+}
+
+// signedTx is a signed and encoded tx along with the sequence and gas limit it was signed with
+type signedTx struct {
+	bytes    []byte
+	sequence uint64
+	gas      uint64
+}
+
+// buildAndSignTx signs the msgs with the given key. If gas is zero the gas limit is computed
+// with gasLimit.
+func (src *Chain) buildAndSignTx(msgs []sdk.Msg, info keys.Info, gas uint64) (tx signedTx, err error) {
+	done := src.UseSDKContext()
+	defer done()
 
-	gp := src.getGasPrices()
-	if src.NewGasPrices != "" {
-		gp, err = sdk.ParseDecCoins(src.NewGasPrices)
+	addr := info.GetAddress()
+	accNum, seq, err := src.sequences.next(src, addr)
+	if err != nil {
+		return tx, err
+	}
+	defer func() {
+		// the sequence won't be used if signing failed
 		if err != nil {
-			return nil, err
+			src.sequences.resync(addr)
 		}
+	}()
+
+	txBldr, err := src.newTxBuilder(accNum, seq)
+	if err != nil {
+		return tx, err
 	}
 
-	ctx := sdkCtx.CLIContext{Client: src.Client}
-	txBldr := auth.NewTxBuilder(
-		auth.DefaultTxEncoder(src.Amino.Codec), acc.GetAccountNumber(),
-		acc.GetSequence(), gas, src.GasAdjustment, true, src.ChainID,
-		src.Memo, sdk.NewCoins(), gp).WithKeybase(src.Keybase)
-	if src.GasAdjustment > 0 {
-		txBldr, err = authclient.EnrichWithGas(txBldr, ctx, msgs)
-		if err != nil {
-			return nil, err
+	if gas == 0 {
+		if gas, err = src.gasLimit(txBldr, msgs); err != nil {
+			return tx, err
 		}
 	}
+	txBldr = txBldr.WithGas(gas)
 
 	if src.debug {
 		msg, err := txBldr.BuildSignMsg(msgs)
 		if err != nil {
-			return nil, err
+			return tx, err
 		}
 		json, _ := src.Cdc.MarshalJSON(auth.NewStdTx(msg.Msgs, msg.Fee, nil, msg.Memo))
 		fmt.Println(string(json))
 	}
 
-	return txBldr.BuildAndSign(src.Key, ckeys.DefaultKeyPass, msgs)
+	out, err := txBldr.BuildAndSign(info.GetName(), ckeys.DefaultKeyPass, msgs)
+	return signedTx{bytes: out, sequence: seq, gas: gas}, err
 }
 
-// BroadcastTxCommit takes the marshaled transaction bytes and broadcasts them
-func (src *Chain) BroadcastTxCommit(txBytes []byte) (sdk.TxResponse, error) {
-	fmt.Println("sending tx...")
-	res, err := sdkCtx.CLIContext{Client: src.Client}.BroadcastTxCommit(txBytes)
+// newTxBuilder returns a tx builder for the given account number and sequence
+// with the configured gas and gas prices, or any overrides of them
+func (src *Chain) newTxBuilder(accNum, seq uint64) (auth.TxBuilder, error) {
+	gp, err := src.CurrentGasPrices()
+	if err != nil {
+		return auth.TxBuilder{}, err
+	}
 
-	if !src.debug {
-		res.RawLog = ""
+	return auth.NewTxBuilder(
+		auth.DefaultTxEncoder(src.Amino.Codec), accNum, seq, src.currentGas(),
+		src.GasAdjustment, true, src.ChainID, src.Memo, sdk.NewCoins(), gp).WithKeybase(src.Keybase), nil
+}
+
+func (src *Chain) currentGas() uint64 {
+	if src.NewGas != 0 {
+		return src.NewGas
 	}
+	return src.Gas
+}
 
-	return res, err
+// FeeForGas returns the fees paid for a tx using the given amount of gas at the current gas prices
+func (src *Chain) FeeForGas(gas uint64) (sdk.Coins, error) {
+	gp, err := src.CurrentGasPrices()
+	if err != nil {
+		return nil, err
+	}
+
+	gasDec := sdk.NewDec(int64(gas))
+	fees := make(sdk.Coins, len(gp))
+	for i, price := range gp {
+		fees[i] = sdk.NewCoin(price.Denom, price.Amount.Mul(gasDec).Ceil().RoundInt())
+	}
+	return fees.Sort(), nil
 }
 
-func (src *Chain) BroadcastTxSync(txBytes []byte) (sdk.TxResponse, error) {
+// MaxTxBytes returns the maximum size of a tx the chain accepts, which is its max block size
+func (src *Chain) MaxTxBytes() (uint64, error) {
+	res, err := src.RPCClient().ConsensusParams(nil)
+	if err != nil {
+		return 0, err
+	}
+	if res.ConsensusParams.Block.MaxBytes <= 0 {
+		return 0, nil
+	}
+	return uint64(res.ConsensusParams.Block.MaxBytes), nil
+}
+
+// encodedTxSizes returns the estimated size of a signed tx without msgs
+// and the number of bytes each of the msgs adds to it when encoded
+func (src *Chain) encodedTxSizes(msgs []sdk.Msg) (base uint64, sizes []uint64, err error) {
+	fee, err := src.FeeForGas(src.currentGas())
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// placeholders with the size of a compressed secp256k1 pubkey and signature
+	sigs := []auth.StdSignature{{PubKey: make([]byte, 33), Signature: make([]byte, 64)}}
+	encode := func(msgs []sdk.Msg) (uint64, error) {
+		bz, err := auth.DefaultTxEncoder(src.Amino.Codec)(auth.NewStdTx(msgs, auth.NewStdFee(src.currentGas(), fee), sigs, src.Memo))
+		return uint64(len(bz)), err
+	}
+
+	empty, err := encode(nil)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	sizes = make([]uint64, len(msgs))
+	for i, msg := range msgs {
+		size, err := encode([]sdk.Msg{msg})
+		if err != nil {
+			return 0, nil, err
+		}
+		sizes[i] = size - empty
+	}
+	return empty + txSizeSlack, sizes, nil
+}
+
+// BroadcastTxCommit takes the marshaled transaction bytes and broadcasts them,
+// returning once they have been committed
+func (src *Chain) BroadcastTxCommit(txBytes []byte) (sdk.TxResponse, error) {
 	fmt.Println("sending tx...")
-	res, err := sdkCtx.CLIContext{Client: src.Client}.BroadcastTxSync(txBytes)
+	res, err := sdkCtx.CLIContext{Client: src.RPCClient()}.BroadcastTxCommit(txBytes)
 
-	if !src.debug {
-		res.RawLog = ""
+	return res, err
+}
+
+// BroadcastTxSync broadcasts the marshaled transaction bytes and returns once they have passed CheckTx
+func (src *Chain) BroadcastTxSync(txBytes []byte) (sdk.TxResponse, error) {
+	// sync txs are sent in bulk by the gun, so they are only noted when debugging
+	if src.debug {
+		src.Log(fmt.Sprintf("- [%s] sending tx...", src.ChainID))
 	}
+	res, err := sdkCtx.CLIContext{Client: src.RPCClient()}.BroadcastTxSync(txBytes)
+
+	return res, err
+}
+
+// BroadcastTxAsync broadcasts the marshaled transaction bytes without waiting for CheckTx
+func (src *Chain) BroadcastTxAsync(txBytes []byte) (sdk.TxResponse, error) {
+	res, err := sdkCtx.CLIContext{Client: src.RPCClient()}.BroadcastTxAsync(txBytes)
 
 	return res, err
 }
@@ -319,19 +576,61 @@ func (src *Chain) Error(err error) {
 
 // Start the client service
 func (src *Chain) Start() error {
-	return src.Client.Start()
+	return src.RPCClient().Start()
 }
 
-// Subscribe returns channel of events given a query
+// Subscribe returns channel of events given a query along with a function
+// that unsubscribes from the query
 func (src *Chain) Subscribe(query string) (<-chan ctypes.ResultEvent, context.CancelFunc, error) {
 	suffix, err := GenerateRandomString(8)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	eventChan, err := src.Client.Subscribe(ctx, fmt.Sprintf("%s-subscriber-%s", src.ChainID, suffix), query, 1000)
-	return eventChan, cancel, err
+	// NOTE: the context only bounds the subscribe request, the subscription
+	// itself lasts until it is unsubscribed or the client is stopped
+	client, subscriber := src.RPCClient(), fmt.Sprintf("%s-subscriber-%s", src.ChainID, suffix)
+	ctx, cancel := context.WithTimeout(context.Background(), src.subscribeTimeout())
+	defer cancel()
+	eventChan, err := client.Subscribe(ctx, subscriber, query, 1000)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return eventChan, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), src.subscribeTimeout())
+		defer cancel()
+		// the subscription is gone anyway if the client has stopped
+		_ = client.Unsubscribe(ctx, subscriber, query)
+	}, nil
+}
+
+func (src *Chain) subscribeTimeout() time.Duration {
+	if src.timeout > 0 {
+		return src.timeout
+	}
+	return 5 * time.Second
+}
+
+// Reconnect replaces the chain's RPC client with a new, started one, for the chain and the copies
+// of it made for its paths. Event subscriptions made with the old client are lost and need to be
+// made again. The old client is stopped once the requests in flight on it have timed out.
+func (src *Chain) Reconnect() error {
+	client, err := newRPCClient(src.RPCAddr, src.timeout)
+	if err != nil {
+		return err
+	}
+	if err = client.Start(); err != nil {
+		return err
+	}
+	old := src.rpc.swap(client)
+	src.Client = client
+	time.AfterFunc(src.subscribeTimeout(), func() {
+		if old.IsRunning() {
+			_ = old.Stop()
+		}
+	})
+	return nil
 }
 
 // KeysDir returns the path to the keys for this chain
@@ -436,6 +735,31 @@ func (src *Chain) Update(key, value string) (out *Chain, err error) {
 			return
 		}
 		out.TrustingPeriod = value
+	case "key-selection":
+		if err = validateKeySelection(value); err != nil {
+			return
+		}
+		out.KeySelection = value
+	case "broadcast-mode":
+		if err = validateBroadcastMode(value); err != nil {
+			return
+		}
+		out.BroadcastMode = value
+	case "confirm-timeout":
+		if _, err = time.ParseDuration(value); err != nil {
+			return
+		}
+		out.ConfirmTimeout = value
+	case "max-gas-prices":
+		if _, err = sdk.ParseDecCoins(value); err != nil {
+			return
+		}
+		out.MaxGasPrices = value
+	case "gas-price-decay":
+		if _, err = time.ParseDuration(value); err != nil {
+			return
+		}
+		out.GasPriceDecay = value
 	default:
 		return out, fmt.Errorf("key %s not found", key)
 	}
@@ -481,7 +805,7 @@ func (src *Chain) SendAndPrint(txs []sdk.Msg, text, indent bool) (err error) {
 	}
 	// SendAndPrint sends the transaction with printing options from the CLI
 	res, err := src.SendMsgs(txs)
-	if err != nil {
+	if err != nil || src.GenOnly {
 		return err
 	}
 
@@ -567,7 +891,7 @@ func (src *Chain) GetTimeout() time.Duration {
 
 // StatusErr returns err unless the chain is ready to go
 func (src *Chain) StatusErr() error {
-	stat, err := src.Client.Status()
+	stat, err := src.RPCClient().Status()
 	switch {
 	case err != nil:
 		return err
@@ -8,8 +8,9 @@ import (
 	ibctypes "github.com/cosmos/cosmos-sdk/x/ibc/types"
 )
 
-// CreateChannel runs the channel creation messages on timeout until they pass
-// TODO: add max retries or something to this function
+// CreateChannel runs the channel creation messages on timeout until they pass. A handshake
+// that has already started is resumed from the state of the channel ends, and nothing is sent
+// if the channel is already open.
 func (src *Chain) CreateChannel(dst *Chain, ordered bool, to time.Duration) error {
 	var order ibctypes.Order
 	if ordered {
@@ -18,6 +19,25 @@ func (src *Chain) CreateChannel(dst *Chain, ordered bool, to time.Duration) erro
 		order = ibctypes.UNORDERED
 	}
 
+	if !src.broadcastDisabled() {
+		awaitPendingTxs(src, dst)
+	}
+
+	chans, err := QueryChannelPair(src, dst, 0, 0)
+	if err != nil {
+		return err
+	}
+	if err = validateChannelEnds(src, dst, order, chans); err != nil {
+		return err
+	}
+	if chans[src.ChainID].Channel.State == ibctypes.OPEN && chans[dst.ChainID].Channel.State == ibctypes.OPEN {
+		src.Log(fmt.Sprintf("★ Channel already open: [%s]chan{%s}port{%s} -> [%s]chan{%s}port{%s}",
+			src.ChainID, src.PathEnd.ChannelID, src.PathEnd.PortID,
+			dst.ChainID, dst.PathEnd.ChannelID, dst.PathEnd.PortID))
+		return nil
+	}
+	resumeHandshake(src, dst, channelHandshake(src, dst, chans))
+
 	ticker := time.NewTicker(to)
 	failures := 0
 	for ; true; <-ticker.C {
@@ -32,10 +52,16 @@ func (src *Chain) CreateChannel(dst *Chain, ordered bool, to time.Duration) erro
 
 		chanSteps.Send(src, dst)
 
+		// nothing was broadcast, so the handshake can't advance past this step
+		if src.broadcastDisabled() {
+			return chanSteps.dryRunError("channel handshake step")
+		}
+
 		switch {
 		// In the case of success and this being the last transaction
 		// debug logging, log created connection and break
 		case chanSteps.success && chanSteps.last:
+			recordChannelProgress(src, dst)
 			chans, err := QueryChannelPair(src, dst, 0, 0)
 			if err != nil {
 				return err
@@ -49,13 +75,14 @@ func (src *Chain) CreateChannel(dst *Chain, ordered bool, to time.Duration) erro
 			return nil
 		// In the case of success, reset the failures counter
 		case chanSteps.success:
+			recordChannelProgress(src, dst)
 			failures = 0
 			continue
 		// In the case of failure, increment the failures counter and exit if this is the 3rd failure
 		case !chanSteps.success:
 			failures++
 			if failures > 2 {
-				return fmt.Errorf("! Channel failed: [%s]chan{%s}port{%s} -> [%s]chan{%s}port{%s}",
+				return fmt.Errorf("! Channel failed, run again to resume: [%s]chan{%s}port{%s} -> [%s]chan{%s}port{%s}",
 					src.ChainID, src.PathEnd.ChannelID, src.PathEnd.PortID,
 					dst.ChainID, dst.PathEnd.ChannelID, dst.PathEnd.PortID)
 			}
@@ -112,8 +139,10 @@ func (src *Chain) CreateChannelStep(dst *Chain, ordering ibctypes.Order) (*Relay
 			src.PathEnd.ChanTry(dst.PathEnd, chans[dcid], src.MustGetAddress()),
 		)
 
-	// Handshake has started on src (1 step done), relay `chanOpenTry` and `updateClient` to dst
-	case chans[scid].Channel.State == ibctypes.INIT && chans[dcid].Channel.State == ibctypes.UNINITIALIZED:
+	// Handshake has started on src (1 step done), relay `chanOpenTry` and `updateClient` to dst. If it
+	// was also started on dst, `chanOpenTry` moves dst's end on from INIT.
+	case chans[scid].Channel.State == ibctypes.INIT &&
+		(chans[dcid].Channel.State == ibctypes.UNINITIALIZED || chans[dcid].Channel.State == ibctypes.INIT):
 		if dst.debug {
 			logChannelStates(dst, src, chans)
 		}
@@ -163,6 +192,29 @@ func (src *Chain) CreateChannelStep(dst *Chain, ordering ibctypes.Order) (*Relay
 			dst.PathEnd.ChanConfirm(chans[scid], dst.MustGetAddress()),
 		)
 		out.last = true
+
+	// Both ends tried to open the channel (2 steps done on each), relay `chanOpenAck` and `updateClient` to both
+	case chans[scid].Channel.State == ibctypes.TRYOPEN && chans[dcid].Channel.State == ibctypes.TRYOPEN:
+		if src.debug {
+			logChannelStates(src, dst, chans)
+		}
+		out.Src = append(out.Src,
+			src.PathEnd.UpdateClient(hs[dcid], src.MustGetAddress()),
+			src.PathEnd.ChanAck(chans[dcid], src.MustGetAddress()),
+		)
+		out.Dst = append(out.Dst,
+			dst.PathEnd.UpdateClient(hs[scid], dst.MustGetAddress()),
+			dst.PathEnd.ChanAck(chans[scid], dst.MustGetAddress()),
+		)
+		out.last = true
+
+	// Handshake is complete
+	case chans[scid].Channel.State == ibctypes.OPEN && chans[dcid].Channel.State == ibctypes.OPEN:
+
+	default:
+		return nil, fmt.Errorf("channel handshake can't be completed from [%s]chan{%s}-{%s} : [%s]chan{%s}-{%s}",
+			scid, src.PathEnd.ChannelID, chans[scid].Channel.State,
+			dcid, dst.PathEnd.ChannelID, chans[dcid].Channel.State)
 	}
 
 	return out, nil
@@ -183,7 +235,14 @@ func (src *Chain) CloseChannel(dst *Chain, to time.Duration) error {
 			break
 		}
 
-		if closeSteps.Send(src, dst); closeSteps.success && closeSteps.last {
+		closeSteps.Send(src, dst)
+
+		// nothing was broadcast, so the handshake can't advance past this step
+		if src.broadcastDisabled() {
+			return closeSteps.dryRunError("channel close step")
+		}
+
+		if closeSteps.success && closeSteps.last {
 			chans, err := QueryChannelPair(src, dst, 0, 0)
 			if err != nil {
 				return err
@@ -24,6 +24,8 @@ func (src *Chain) CreateClients(dst *Chain) (err error) {
 			src.logCreateClient(dst, dstH.GetHeight())
 		}
 		clients.Src = append(clients.Src, src.PathEnd.CreateClient(dstH, dst.GetTrustingPeriod(), src.MustGetAddress()))
+	} else if err = reuseClient(src, dst, srcCs); err != nil {
+		return err
 	}
 
 	// Create client for src on dst if it doesn't exist
@@ -38,15 +40,36 @@ func (src *Chain) CreateClients(dst *Chain) (err error) {
 			dst.logCreateClient(src, srcH.GetHeight())
 		}
 		clients.Dst = append(clients.Dst, dst.PathEnd.CreateClient(srcH, src.GetTrustingPeriod(), dst.MustGetAddress()))
+	} else if err = reuseClient(dst, src, dstCs); err != nil {
+		return err
 	}
 
 	// Send msgs to both chains
 	if clients.Ready() {
-		if clients.Send(src, dst); clients.success {
-			src.Log(fmt.Sprintf("★ Clients created: [%s]client(%s) and [%s]client(%s)",
-				src.ChainID, src.PathEnd.ClientID, dst.ChainID, dst.PathEnd.ClientID))
+		if clients.Send(src, dst); src.broadcastDisabled() {
+			return clients.dryRunError("client creation")
+		} else if !clients.success {
+			return fmt.Errorf("! Client creation failed, run again to resume: [%s]client(%s) and [%s]client(%s)",
+				src.ChainID, src.PathEnd.ClientID, dst.ChainID, dst.PathEnd.ClientID)
 		}
+		src.Log(fmt.Sprintf("★ Clients created: [%s]client(%s) and [%s]client(%s)",
+			src.ChainID, src.PathEnd.ClientID, dst.ChainID, dst.PathEnd.ClientID))
 	}
 
 	return nil
 }
+
+// reuseClient checks that the existing client of the path on src tracks dst and is still active,
+// so that the handshakes can be run over it
+func reuseClient(src, dst *Chain, cs *clientTypes.StateResponse) error {
+	if chainID := cs.ClientState.GetChainID(); chainID != dst.ChainID {
+		return fmt.Errorf("[%s]client{%s} already exists for chain %s, not %s",
+			src.ChainID, src.PathEnd.ClientID, chainID, dst.ChainID)
+	}
+	if _, ok := activeClient(cs.ClientState, dst.ChainID); !ok {
+		return fmt.Errorf("[%s]client{%s} of [%s] is frozen or past its trusting period, "+
+			"link the path with --reuse or generate a new one", src.ChainID, src.PathEnd.ClientID, dst.ChainID)
+	}
+	src.Log(fmt.Sprintf("- [%s]client{%s} of [%s] already exists, reusing it", src.ChainID, src.PathEnd.ClientID, dst.ChainID))
+	return nil
+}
@@ -0,0 +1,127 @@
+package relayer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+const (
+	// BroadcastBlock waits for the tx to be committed in a block
+	BroadcastBlock = "block"
+
+	// BroadcastSync waits for the tx to pass CheckTx and then polls for its inclusion
+	BroadcastSync = "sync"
+
+	// BroadcastAsync returns as soon as the tx is sent and then polls for its inclusion
+	BroadcastAsync = "async"
+
+	defaultConfirmTimeout = time.Minute
+
+	// maxUnconfirmedTxs is the most txs a node lists from its mempool
+	maxUnconfirmedTxs = 100
+
+	// rpcInternalErrorCode is the JSON-RPC code of the errors returned by the node's handlers
+	rpcInternalErrorCode = -32603
+)
+
+// confirmPollInterval is how often the chain is queried for a tx that hasn't been included yet
+var confirmPollInterval = time.Second
+
+func validateBroadcastMode(mode string) error {
+	switch mode {
+	case "", BroadcastBlock, BroadcastSync, BroadcastAsync:
+		return nil
+	default:
+		return fmt.Errorf("invalid broadcast-mode %s, expected %s, %s or %s", mode, BroadcastBlock, BroadcastSync, BroadcastAsync)
+	}
+}
+
+// GetConfirmTimeout returns how long to wait for a broadcast tx to be included in a block
+func (src *Chain) GetConfirmTimeout() time.Duration {
+	if timeout, err := time.ParseDuration(src.ConfirmTimeout); err == nil && timeout > 0 {
+		return timeout
+	}
+	return defaultConfirmTimeout
+}
+
+// broadcastTx broadcasts the tx with the chain's broadcast mode
+func (src *Chain) broadcastTx(txBytes []byte) (sdk.TxResponse, error) {
+	switch src.BroadcastMode {
+	case BroadcastSync:
+		return src.BroadcastTxSync(txBytes)
+	case BroadcastAsync:
+		return src.BroadcastTxAsync(txBytes)
+	default:
+		return src.BroadcastTxCommit(txBytes)
+	}
+}
+
+// WaitForTx polls the chain for the tx with the given hash until it is included
+// in a block or the confirm timeout expires
+func (src *Chain) WaitForTx(hash string) (sdk.TxResponse, error) {
+	timeout := time.After(src.GetConfirmTimeout())
+	ticker := time.NewTicker(confirmPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			res, err := src.QueryTx(hash)
+			switch {
+			case err == nil:
+				if !src.debug {
+					res.RawLog = ""
+				}
+				return res, nil
+			case !txNotFound(err):
+				src.Error(fmt.Errorf("failed to query tx %s: %w", hash, err))
+			}
+		case <-timeout:
+			return sdk.TxResponse{TxHash: hash}, fmt.Errorf("tx %s wasn't included in a block within %s",
+				hash, src.GetConfirmTimeout())
+		}
+	}
+}
+
+// txDropped returns true if the tx with the given hash is known to have left the mempool without
+// being committed. It returns false if the tx may still be pending, e.g. if the mempool holds more
+// txs than can be listed or the node can't be queried.
+func (src *Chain) txDropped(hash string) bool {
+	res, err := src.RPCClient().UnconfirmedTxs(maxUnconfirmedTxs)
+	if err != nil || res.Count < res.Total {
+		return false
+	}
+	for _, tx := range res.Txs {
+		if txHash(tx) == hash {
+			return false
+		}
+	}
+
+	// it may have been committed since the wait timed out
+	_, err = src.QueryTx(hash)
+	return txNotFound(err)
+}
+
+// txNotFound returns true if the node reported that it has no tx with the queried hash, rather
+// than the query failing. The node reports it as an internal error, so only those are matched.
+func txNotFound(err error) bool {
+	var rpcErr *rpctypes.RPCError
+	return errors.As(err, &rpcErr) && rpcErr.Code == rpcInternalErrorCode && strings.Contains(rpcErr.Data, "not found")
+}
+
+// pendingInclusion returns true if the tx was broadcast without waiting for it to be committed
+// and hasn't been rejected by CheckTx
+func pendingInclusion(res sdk.TxResponse, err error) bool {
+	return err == nil && res.Code == 0 && res.Height == 0
+}
+
+// txHash returns the hex encoded hash of the tx
+func txHash(txBytes []byte) string {
+	return fmt.Sprintf("%X", tmtypes.Tx(txBytes).Hash())
+}
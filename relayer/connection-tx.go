@@ -8,9 +8,29 @@ import (
 	ibctypes "github.com/cosmos/cosmos-sdk/x/ibc/types"
 )
 
-// CreateConnection runs the connection creation messages on timeout until they pass
-// TODO: add max retries or something to this function
+// CreateConnection runs the connection creation messages on timeout until they pass. A handshake
+// that has already started is resumed from the state of the connection ends, and nothing is sent
+// if the connection is already open.
 func (src *Chain) CreateConnection(dst *Chain, to time.Duration) error {
+	if !src.broadcastDisabled() {
+		awaitPendingTxs(src, dst)
+	}
+
+	conns, err := QueryConnectionPair(src, dst, 0, 0)
+	if err != nil {
+		return err
+	}
+	if err = validateConnectionEnds(src, dst, conns); err != nil {
+		return err
+	}
+	if conns[src.ChainID].Connection.State == ibctypes.OPEN && conns[dst.ChainID].Connection.State == ibctypes.OPEN {
+		src.Log(fmt.Sprintf("★ Connection already open: [%s]client{%s}conn{%s} -> [%s]client{%s}conn{%s}",
+			src.ChainID, src.PathEnd.ClientID, src.PathEnd.ConnectionID,
+			dst.ChainID, dst.PathEnd.ClientID, dst.PathEnd.ConnectionID))
+		return nil
+	}
+	resumeHandshake(src, dst, connectionHandshake(src, dst, conns))
+
 	ticker := time.NewTicker(to)
 	failed := 0
 	for ; true; <-ticker.C {
@@ -25,10 +45,16 @@ func (src *Chain) CreateConnection(dst *Chain, to time.Duration) error {
 
 		connSteps.Send(src, dst)
 
+		// nothing was broadcast, so the handshake can't advance past this step
+		if src.broadcastDisabled() {
+			return connSteps.dryRunError("connection handshake step")
+		}
+
 		switch {
 		// In the case of success and this being the last transaction
 		// debug logging, log created connection and break
 		case connSteps.success && connSteps.last:
+			recordConnectionProgress(src, dst)
 			if src.debug {
 				conns, err := QueryConnectionPair(src, dst, 0, 0)
 				if err != nil {
@@ -43,13 +69,14 @@ func (src *Chain) CreateConnection(dst *Chain, to time.Duration) error {
 			return nil
 		// In the case of success, reset the failures counter
 		case connSteps.success:
+			recordConnectionProgress(src, dst)
 			failed = 0
 			continue
 		// In the case of failure, increment the failures counter and exit if this is the 3rd failure
 		case !connSteps.success:
 			failed++
 			if failed > 2 {
-				return fmt.Errorf("! Connection failed: [%s]client{%s}conn{%s} -> [%s]client{%s}conn{%s}",
+				return fmt.Errorf("! Connection failed, run again to resume: [%s]client{%s}conn{%s} -> [%s]client{%s}conn{%s}",
 					src.ChainID, src.PathEnd.ClientID, src.PathEnd.ConnectionID,
 					dst.ChainID, dst.PathEnd.ClientID, dst.PathEnd.ConnectionID)
 			}
@@ -96,8 +123,11 @@ func (src *Chain) CreateConnectionStep(dst *Chain) (*RelayMsgs, error) {
 	}
 
 	// TODO: log these heights or something about client state? debug?
-	if cs[scid] == nil || cs[dcid] == nil {
-		return nil, err
+	switch {
+	case cs[scid] == nil:
+		return nil, fmt.Errorf("[%s]client{%s} doesn't exist", scid, src.PathEnd.ClientID)
+	case cs[dcid] == nil:
+		return nil, fmt.Errorf("[%s]client{%s} doesn't exist", dcid, dst.PathEnd.ClientID)
 	}
 
 	// Store the heights
@@ -128,8 +158,10 @@ func (src *Chain) CreateConnectionStep(dst *Chain) (*RelayMsgs, error) {
 			src.PathEnd.ConnTry(dst.PathEnd, conn[dcid], cons[dcid], dstConsH, src.MustGetAddress()),
 		)
 
-	// Handshake has started on src (1 step done), relay `connOpenTry` and `updateClient` on dst
-	case conn[scid].Connection.State == ibctypes.INIT && conn[dcid].Connection.State == ibctypes.UNINITIALIZED:
+	// Handshake has started on src (1 step done), relay `connOpenTry` and `updateClient` on dst. If it
+	// was also started on dst, `connOpenTry` moves dst's end on from INIT.
+	case conn[scid].Connection.State == ibctypes.INIT &&
+		(conn[dcid].Connection.State == ibctypes.UNINITIALIZED || conn[dcid].Connection.State == ibctypes.INIT):
 		if dst.debug {
 			logConnectionStates(dst, src, conn)
 		}
@@ -179,6 +211,15 @@ func (src *Chain) CreateConnectionStep(dst *Chain) (*RelayMsgs, error) {
 			dst.PathEnd.ConnConfirm(conn[scid], dst.MustGetAddress()),
 		)
 		out.last = true
+
+	// Handshake is complete
+	case conn[scid].Connection.State == ibctypes.OPEN && conn[dcid].Connection.State == ibctypes.OPEN:
+
+	// Both ends tried to open the connection, which `connOpenAck` can't complete
+	default:
+		return nil, fmt.Errorf("connection handshake can't be completed from [%s]conn{%s}-{%s} : [%s]conn{%s}-{%s}",
+			scid, src.PathEnd.ConnectionID, conn[scid].Connection.State,
+			dcid, dst.PathEnd.ConnectionID, conn[dcid].Connection.State)
 	}
 
 	return out, nil
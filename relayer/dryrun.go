@@ -0,0 +1,64 @@
+package relayer
+
+import (
+	"fmt"
+	"strings"
+
+	sdkCtx "github.com/cosmos/cosmos-sdk/client/context"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authclient "github.com/cosmos/cosmos-sdk/x/auth/client"
+)
+
+// simulateTx simulates the signed tx against the chain's node instead of broadcasting it, and logs
+// the gas it used along with the events of each msg or the error it failed with. The returned
+// response carries the gas and logs of the simulation.
+func (src *Chain) simulateTx(txBytes []byte, gas uint64, msgs []sdk.Msg) (sdk.TxResponse, error) {
+	simRes, _, err := authclient.CalculateGas(
+		sdkCtx.CLIContext{Client: src.RPCClient()}.QueryWithData, src.Amino.Codec, txBytes, src.GasAdjustment)
+	if err != nil {
+		src.logDryRunFailed(msgs, err)
+		return sdk.TxResponse{}, fmt.Errorf("simulation failed: %w", err)
+	}
+
+	res := sdk.TxResponse{
+		TxHash:    txHash(txBytes),
+		GasWanted: int64(gas),
+		GasUsed:   int64(simRes.GasUsed),
+	}
+	if simRes.Result != nil {
+		res.RawLog = simRes.Result.Log
+		if logs, err := sdk.ParseABCILogs(simRes.Result.Log); err == nil {
+			res.Logs = logs
+		}
+	}
+
+	src.logDryRun(msgs, res)
+	return res, nil
+}
+
+// broadcastDisabled returns true if the chain's txs are simulated or written out
+// unsigned instead of being broadcast
+func (src *Chain) broadcastDisabled() bool {
+	return src.DryRun || src.GenOnly
+}
+
+// dryRunError returns an error if any of the msgs failed to be simulated or written out
+func (r *RelayMsgs) dryRunError(step string) error {
+	if r.success {
+		return nil
+	}
+	return fmt.Errorf("%s failed without broadcasting", step)
+}
+
+// formatEvents renders the events as type{key=value,...} separated by spaces
+func formatEvents(events sdk.StringEvents) string {
+	out := make([]string, 0, len(events))
+	for _, ev := range events {
+		attrs := make([]string, 0, len(ev.Attributes))
+		for _, attr := range ev.Attributes {
+			attrs = append(attrs, fmt.Sprintf("%s=%s", attr.Key, attr.Value))
+		}
+		out = append(out, fmt.Sprintf("%s{%s}", ev.Type, strings.Join(attrs, ",")))
+	}
+	return strings.Join(out, " ")
+}
@@ -15,12 +15,21 @@ import (
 
 // SendMsgWithKey allows the user to specify which relayer key will sign the message
 func (src *Chain) SendMsgWithKey(datagram sdk.Msg, keyName string) (res sdk.TxResponse, err error) {
+	info, err := src.Keybase.Key(keyName)
+	if err != nil {
+		return res, err
+	}
+
 	var out []byte
 	if out, err = src.BuildAndSignTxWithKey([]sdk.Msg{datagram}, keyName); err != nil {
 		return res, err
 	}
-	return src.BroadcastTxCommit(out)
-
+	res, err = src.BroadcastTxCommit(out)
+	src.sequences.broadcastResult(info.GetAddress(), res, err)
+	if !src.debug {
+		res.RawLog = ""
+	}
+	return res, err
 }
 
 // BuildAndSignTxWithKey allows the user to specify which relayer key will sign the message
@@ -35,16 +44,20 @@ func (src *Chain) BuildAndSignTxWithKey(datagram []sdk.Msg, keyName string) ([]b
 	done := src.UseSDKContext()
 	defer done()
 
-	acc, err := auth.NewAccountRetriever(src.Cdc, src).GetAccount(info.GetAddress())
+	accNum, seq, err := src.sequences.next(src, info.GetAddress())
 	if err != nil {
 		return nil, err
 	}
 
-	return auth.NewTxBuilder(
-		auth.DefaultTxEncoder(src.Amino.Codec), acc.GetAccountNumber(),
-		acc.GetSequence(), src.Gas, src.GasAdjustment, false, src.ChainID,
+	out, err := auth.NewTxBuilder(
+		auth.DefaultTxEncoder(src.Amino.Codec), accNum,
+		seq, src.Gas, src.GasAdjustment, false, src.ChainID,
 		src.Memo, sdk.NewCoins(), src.getGasPrices()).WithKeybase(src.Keybase).
 		BuildAndSign(info.GetName(), ckeys.DefaultKeyPass, datagram)
+	if err != nil {
+		src.sequences.resync(info.GetAddress())
+	}
+	return out, err
 }
 
 // FaucetHandler listens for addresses
@@ -0,0 +1,266 @@
+package relayer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chanTypes "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/types"
+	xferTypes "github.com/cosmos/cosmos-sdk/x/ibc/20-transfer/types"
+)
+
+const (
+	naiveOptFeeMode     = "fee-mode"
+	naiveOptMinValue    = "min-value"
+	naiveOptSkippedFile = "skipped-file"
+
+	// FeeModeDefer leaves packets whose value is below the relay cost unrelayed,
+	// so that they are reconsidered the next time the backlog is relayed
+	FeeModeDefer = "defer"
+
+	// FeeModeSkip records packets whose value is below the relay cost as
+	// skipped and never relays them
+	FeeModeSkip = "skip"
+)
+
+// SkippedPacket is a packet that wasn't relayed because its value was below the cost of
+// relaying it. ChainID is the chain the msg of type MsgType would have been sent to.
+type SkippedPacket struct {
+	ChainID   string    `json:"chain-id" yaml:"chain-id"`
+	PortID    string    `json:"port-id" yaml:"port-id"`
+	ChannelID string    `json:"channel-id" yaml:"channel-id"`
+	Sequence  uint64    `json:"sequence" yaml:"sequence"`
+	MsgType   string    `json:"msg-type" yaml:"msg-type"`
+	Sender    string    `json:"sender" yaml:"sender"`
+	Receiver  string    `json:"receiver" yaml:"receiver"`
+	Amount    sdk.Coins `json:"amount" yaml:"amount"`
+	Threshold sdk.Coins `json:"threshold" yaml:"threshold"`
+	Time      time.Time `json:"time" yaml:"time"`
+}
+
+func (sp SkippedPacket) key() string {
+	return fmt.Sprintf("%s/%s/%d/%s", sp.PortID, sp.ChannelID, sp.Sequence, sp.MsgType)
+}
+
+// SkippedPackets returns the packets skipped by the strategy since it was started
+func (nrs *NaiveStrategy) SkippedPackets() []SkippedPacket {
+	nrs.skippedMu.Lock()
+	defer nrs.skippedMu.Unlock()
+
+	out := make([]SkippedPacket, 0, len(nrs.skipped))
+	for _, sp := range nrs.skipped {
+		out = append(out, sp)
+	}
+	return out
+}
+
+// selectByValue returns the packet msgs to send to c whose transferred value covers their share of
+// the estimated fee for sending them, or the configured MinValue if that is higher. The other msgs
+// are deferred or skipped depending on the FeeMode. Only MsgRecvPackets of ICS20 transfers are checked.
+func (nrs *NaiveStrategy) selectByValue(c, counterparty *Chain, msgs []sdk.Msg, sh *SyncHeaders) []sdk.Msg {
+	if nrs.FeeMode == "" || len(msgs) == 0 {
+		return msgs
+	}
+
+	thresholds, err := nrs.valueThresholds(c, counterparty, msgs, sh)
+	if err != nil {
+		c.Error(fmt.Errorf("failed to estimate relay fees, only checking min-value: %w", err))
+		thresholds = nrs.MinValue
+	}
+	return nrs.selectCovered(c, msgs, thresholds)
+}
+
+// selectCovered returns the msgs whose received transfers carry at least the thresholds,
+// deferring or skipping the others
+func (nrs *NaiveStrategy) selectCovered(c *Chain, msgs []sdk.Msg, thresholds sdk.Coins) []sdk.Msg {
+	out := make([]sdk.Msg, 0, len(msgs))
+	for _, msg := range msgs {
+		packet, ok := recvPacket(msg)
+		if !ok {
+			out = append(out, msg)
+			continue
+		}
+
+		var xfer xferTypes.FungibleTokenPacketData
+		if err := xferTypes.ModuleCdc.UnmarshalJSON(packet.GetData(), &xfer); err != nil {
+			out = append(out, msg)
+			continue
+		}
+
+		sp := SkippedPacket{
+			ChainID:   c.ChainID,
+			PortID:    packet.GetSourcePort(),
+			ChannelID: packet.GetSourceChannel(),
+			Sequence:  packet.GetSequence(),
+			MsgType:   msg.Type(),
+			Sender:    xfer.Sender,
+			Receiver:  xfer.Receiver,
+			Amount:    xfer.Amount,
+			Threshold: thresholds,
+			Time:      time.Now(),
+		}
+		switch {
+		case nrs.isSkipped(sp):
+			continue
+		case coversValue(transferAmount(xfer.Amount, packetDenomPrefixes(packet)...), thresholds):
+			out = append(out, msg)
+		case nrs.FeeMode == FeeModeSkip:
+			c.logPacketBelowValue(sp, "skipping")
+			nrs.recordSkipped(c, sp)
+		default:
+			c.logPacketBelowValue(sp, "deferring")
+		}
+	}
+	return out
+}
+
+// valueThresholds estimates the fee for sending the msgs to c and returns the
+// larger of each received packet's share of it and MinValue for each denom
+func (nrs *NaiveStrategy) valueThresholds(c, counterparty *Chain, msgs []sdk.Msg, sh *SyncHeaders) (sdk.Coins, error) {
+	var packets int64
+	for _, msg := range msgs {
+		if _, ok := recvPacket(msg); ok {
+			packets++
+		}
+	}
+	if packets == 0 {
+		return nrs.MinValue, nil
+	}
+
+	// the msgs are sent along with an update client
+	simMsgs := append([]sdk.Msg{
+		c.PathEnd.UpdateClient(sh.GetHeader(counterparty.ChainID), c.MustGetAddress()),
+	}, msgs...)
+	gas, err := c.EstimateGas(simMsgs)
+	if err != nil {
+		return nil, err
+	}
+
+	fee, err := c.FeeForGas(gas)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholds := sdk.NewCoins()
+	for _, coin := range fee {
+		share := coin.Amount.QuoRaw(packets)
+		if min := nrs.MinValue.AmountOf(coin.Denom); min.GT(share) {
+			share = min
+		}
+		thresholds = thresholds.Add(sdk.NewCoin(coin.Denom, share))
+	}
+	for _, min := range nrs.MinValue {
+		if thresholds.AmountOf(min.Denom).IsZero() {
+			thresholds = thresholds.Add(min)
+		}
+	}
+	return thresholds, nil
+}
+
+func (nrs *NaiveStrategy) isSkipped(sp SkippedPacket) bool {
+	nrs.skippedMu.Lock()
+	defer nrs.skippedMu.Unlock()
+	_, ok := nrs.skipped[sp.key()]
+	return ok
+}
+
+// recordSkipped keeps the skipped packet for review and appends it to the SkippedFile if one is set
+func (nrs *NaiveStrategy) recordSkipped(c *Chain, sp SkippedPacket) {
+	nrs.skippedMu.Lock()
+	defer nrs.skippedMu.Unlock()
+
+	if nrs.skipped == nil {
+		nrs.skipped = make(map[string]SkippedPacket)
+	}
+	nrs.skipped[sp.key()] = sp
+
+	if nrs.SkippedFile == "" {
+		return
+	}
+
+	out, err := json.Marshal(sp)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	f, err := os.OpenFile(nrs.SkippedFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer f.Close()
+
+	if _, err = f.Write(append(out, '\n')); err != nil {
+		c.Error(err)
+	}
+}
+
+// coversValue returns true if any coin of amount meets its denom's threshold,
+// or if none of the denoms in amount have a threshold
+func coversValue(amount, thresholds sdk.Coins) bool {
+	valued := false
+	for _, coin := range amount {
+		for _, min := range thresholds {
+			if coin.Denom != min.Denom {
+				continue
+			}
+			if coin.Amount.GTE(min.Amount) {
+				return true
+			}
+			valued = true
+		}
+	}
+	return !valued
+}
+
+// transferAmount returns the amount of a transfer with the first of the port/channel prefixes
+// that its denoms carry stripped, so that they can be compared with the chains' own denoms
+func transferAmount(amount sdk.Coins, prefixes ...string) sdk.Coins {
+	out := make(sdk.Coins, 0, len(amount))
+	for _, coin := range amount {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(coin.Denom, prefix) {
+				coin.Denom = strings.TrimPrefix(coin.Denom, prefix)
+				break
+			}
+		}
+		out = append(out, coin)
+	}
+	return out.Sort()
+}
+
+// packetDenomPrefixes returns the denom prefixes of the ends of the packet's channel, a
+// transfer's denoms carry the prefix of the receiving end if the coins are native to the
+// sender and the prefix of the sending end if they are returning to their source
+func packetDenomPrefixes(packet chanTypes.Packet) []string {
+	return []string{
+		xferTypes.GetDenomPrefix(packet.GetDestPort(), packet.GetDestChannel()),
+		xferTypes.GetDenomPrefix(packet.GetSourcePort(), packet.GetSourceChannel()),
+	}
+}
+
+// msgPacket returns the packet relayed by a packet, timeout or acknowledgement msg
+func msgPacket(msg sdk.Msg) (chanTypes.Packet, bool) {
+	switch m := msg.(type) {
+	case chanTypes.MsgPacket:
+		return m.Packet, true
+	case chanTypes.MsgTimeout:
+		return m.Packet, true
+	case chanTypes.MsgAcknowledgement:
+		return m.Packet, true
+	default:
+		return chanTypes.Packet{}, false
+	}
+}
+
+// recvPacket returns the packet received by a MsgRecvPacket
+func recvPacket(msg sdk.Msg) (chanTypes.Packet, bool) {
+	if m, ok := msg.(chanTypes.MsgPacket); ok {
+		return m.Packet, true
+	}
+	return chanTypes.Packet{}, false
+}
@@ -0,0 +1,89 @@
+package relayer
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chanTypes "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/types"
+	xferTypes "github.com/cosmos/cosmos-sdk/x/ibc/20-transfer/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// transferMsg returns the MsgRecvPacket of a transfer of amount sent over transfer/srcxfer to
+// transfer/dstxfer, as the sending chain's transfer module builds it
+func transferMsg(seq uint64, amount string) chanTypes.MsgPacket {
+	coins, err := sdk.ParseCoins(amount)
+	if err != nil {
+		panic(err)
+	}
+	data := xferTypes.NewFungibleTokenPacketData(coins, "sender", "receiver")
+	return chanTypes.MsgPacket{
+		Packet: chanTypes.NewPacket(data.GetBytes(), seq, "transfer", "srcxfer", "transfer", "dstxfer", 100, 0),
+	}
+}
+
+func TestSelectCovered(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		amount     string
+		thresholds string
+		relayed    bool
+		skipped    bool
+	}{
+		{"native coins covering", FeeModeDefer, "100transfer/dstxfer/stake", "100stake", true, false},
+		{"native coins deferred", FeeModeDefer, "99transfer/dstxfer/stake", "100stake", false, false},
+		{"native coins skipped", FeeModeSkip, "99transfer/dstxfer/stake", "100stake", false, true},
+		{"returning coins covering", FeeModeDefer, "100transfer/srcxfer/stake", "100stake", true, false},
+		{"returning coins skipped", FeeModeSkip, "1transfer/srcxfer/stake", "100stake", false, true},
+		{"other channel isn't stripped", FeeModeSkip, "1transfer/otherxfer/stake", "100stake", true, false},
+		{"no threshold for the denom", FeeModeSkip, "1transfer/dstxfer/ucoin", "100stake", true, false},
+		{"any covering denom", FeeModeSkip, "5transfer/dstxfer/ucoin", "100stake,5ucoin", true, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Chain{ChainID: "dst", logger: log.NewNopLogger()}
+			nrs := &NaiveStrategy{FeeMode: tc.mode}
+			thresholds, err := sdk.ParseCoins(tc.thresholds)
+			require.NoError(t, err)
+
+			msgs := []sdk.Msg{transferMsg(1, tc.amount)}
+			out := nrs.selectCovered(c, msgs, thresholds)
+			if tc.relayed {
+				require.Equal(t, msgs, out)
+			} else {
+				require.Empty(t, out)
+			}
+
+			skipped := nrs.SkippedPackets()
+			if !tc.skipped {
+				require.Empty(t, skipped)
+				return
+			}
+			require.Len(t, skipped, 1)
+			require.Equal(t, uint64(1), skipped[0].Sequence)
+			require.Equal(t, "srcxfer", skipped[0].ChannelID)
+
+			// skipped packets stay skipped, whatever the thresholds
+			require.Empty(t, nrs.selectCovered(c, msgs, sdk.NewCoins()))
+		})
+	}
+}
+
+func TestSelectCoveredPassesOtherMsgs(t *testing.T) {
+	c := &Chain{ChainID: "dst", logger: log.NewNopLogger()}
+	nrs := &NaiveStrategy{FeeMode: FeeModeSkip}
+	thresholds := sdk.NewCoins(sdk.NewInt64Coin("stake", 100))
+
+	packet := transferMsg(1, "1transfer/dstxfer/stake").Packet
+	notTransfer := chanTypes.MsgPacket{Packet: packet}
+	notTransfer.Packet.Data = []byte("not a transfer")
+	msgs := []sdk.Msg{
+		chanTypes.MsgTimeout{Packet: packet},
+		chanTypes.MsgAcknowledgement{Packet: packet},
+		notTransfer,
+	}
+	require.Equal(t, msgs, nrs.selectCovered(c, msgs, thresholds))
+}
@@ -0,0 +1,120 @@
+package relayer
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	xferTypes "github.com/cosmos/cosmos-sdk/x/ibc/20-transfer/types"
+)
+
+// PacketFilter defines which packets a strategy relays over a path. The sender, receiver
+// and amount rules apply to ICS20 transfer packets. Packets that can't be decoded as
+// transfers are only relayed if no sender or receiver allow list is set.
+type PacketFilter struct {
+	SenderAllow   []string `json:"sender-allow,omitempty" yaml:"sender-allow,omitempty"`
+	SenderDeny    []string `json:"sender-deny,omitempty" yaml:"sender-deny,omitempty"`
+	ReceiverAllow []string `json:"receiver-allow,omitempty" yaml:"receiver-allow,omitempty"`
+	ReceiverDeny  []string `json:"receiver-deny,omitempty" yaml:"receiver-deny,omitempty"`
+
+	// MinAmount is the minimum amount of each denom a transfer must carry, e.g. "100stake,10ucoin".
+	// Denoms that aren't listed aren't checked.
+	MinAmount string `json:"min-amount,omitempty" yaml:"min-amount,omitempty"`
+
+	// MaxDataSize is the maximum size in bytes of the packet data, zero means no limit
+	MaxDataSize uint64 `json:"max-data-size,omitempty" yaml:"max-data-size,omitempty"`
+}
+
+// Validate checks that the filter can be applied
+func (pf *PacketFilter) Validate() error {
+	if pf == nil {
+		return nil
+	}
+	if _, err := sdk.ParseCoins(pf.MinAmount); err != nil {
+		return fmt.Errorf("invalid filter min-amount %s: %w", pf.MinAmount, err)
+	}
+	return nil
+}
+
+// Check returns an error describing why the packet data doesn't pass the filter,
+// or nil if the packet should be relayed. The port/channel prefixes of the path's
+// ends are stripped from the transfer's denoms before they're compared with min-amount.
+func (pf *PacketFilter) Check(data []byte, denomPrefixes ...string) error {
+	if pf == nil {
+		return nil
+	}
+
+	if pf.MaxDataSize > 0 && uint64(len(data)) > pf.MaxDataSize {
+		return fmt.Errorf("packet data size %d exceeds max-data-size %d", len(data), pf.MaxDataSize)
+	}
+
+	var xfer xferTypes.FungibleTokenPacketData
+	if err := xferTypes.ModuleCdc.UnmarshalJSON(data, &xfer); err != nil {
+		if len(pf.SenderAllow) > 0 || len(pf.ReceiverAllow) > 0 {
+			return fmt.Errorf("packet data is not a transfer: %w", err)
+		}
+		return nil
+	}
+
+	if err := checkAllowDeny("sender", xfer.Sender, pf.SenderAllow, pf.SenderDeny); err != nil {
+		return err
+	}
+	if err := checkAllowDeny("receiver", xfer.Receiver, pf.ReceiverAllow, pf.ReceiverDeny); err != nil {
+		return err
+	}
+
+	// min-amount is checked in Validate
+	minAmount, _ := sdk.ParseCoins(pf.MinAmount)
+	amounts := transferAmount(xfer.Amount, denomPrefixes...)
+	for _, min := range minAmount {
+		if amount := amounts.AmountOf(min.Denom); amount.IsPositive() && amount.LT(min.Amount) {
+			return fmt.Errorf("transfer amount %s%s is less than min-amount %s", amount, min.Denom, min)
+		}
+	}
+
+	return nil
+}
+
+// filterPackets returns the packets relayed between c and counterparty that pass the filter,
+// logging the ones that don't. Only packets to be received are filtered, timeouts and
+// acknowledgements always pass so that escrowed funds are released.
+func (pf *PacketFilter) filterPackets(c, counterparty *Chain, rlyPackets []relayPacket) []relayPacket {
+	if pf == nil {
+		return rlyPackets
+	}
+
+	prefixes := []string{
+		xferTypes.GetDenomPrefix(c.PathEnd.PortID, c.PathEnd.ChannelID),
+		xferTypes.GetDenomPrefix(counterparty.PathEnd.PortID, counterparty.PathEnd.ChannelID),
+	}
+	out := make([]relayPacket, 0, len(rlyPackets))
+	for _, rp := range rlyPackets {
+		if _, recv := rp.(*relayMsgRecvPacket); !recv {
+			out = append(out, rp)
+			continue
+		}
+		if err := pf.Check(rp.Data(), prefixes...); err != nil {
+			c.logPacketFiltered(rp, err)
+			continue
+		}
+		out = append(out, rp)
+	}
+	return out
+}
+
+func checkAllowDeny(field, addr string, allow, deny []string) error {
+	for _, denied := range deny {
+		if addr == denied {
+			return fmt.Errorf("%s %s is denied", field, addr)
+		}
+	}
+
+	if len(allow) == 0 {
+		return nil
+	}
+	for _, allowed := range allow {
+		if addr == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s %s is not allowed", field, addr)
+}
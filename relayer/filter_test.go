@@ -0,0 +1,73 @@
+package relayer
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	xferTypes "github.com/cosmos/cosmos-sdk/x/ibc/20-transfer/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func transferData(amount, sender, receiver string) []byte {
+	coins, err := sdk.ParseCoins(amount)
+	if err != nil {
+		panic(err)
+	}
+	return xferTypes.NewFungibleTokenPacketData(coins, sender, receiver).GetBytes()
+}
+
+func TestPacketFilterCheck(t *testing.T) {
+	prefixes := []string{"transfer/srcxfer/", "transfer/dstxfer/"}
+	tests := []struct {
+		name   string
+		filter PacketFilter
+		data   []byte
+		pass   bool
+	}{
+		{"empty filter", PacketFilter{}, transferData("1transfer/dstxfer/stake", "a", "b"), true},
+		{"native coins over min", PacketFilter{MinAmount: "100stake"}, transferData("100transfer/dstxfer/stake", "a", "b"), true},
+		{"native coins under min", PacketFilter{MinAmount: "100stake"}, transferData("99transfer/dstxfer/stake", "a", "b"), false},
+		{"returning coins under min", PacketFilter{MinAmount: "100stake"}, transferData("99transfer/srcxfer/stake", "a", "b"), false},
+		{"other channel's coins", PacketFilter{MinAmount: "100stake"}, transferData("1transfer/otherxfer/stake", "a", "b"), true},
+		{"unlisted denom", PacketFilter{MinAmount: "100stake"}, transferData("1transfer/dstxfer/ucoin", "a", "b"), true},
+		{"denied sender", PacketFilter{SenderDeny: []string{"a"}}, transferData("1transfer/dstxfer/stake", "a", "b"), false},
+		{"allowed receiver", PacketFilter{ReceiverAllow: []string{"b"}}, transferData("1transfer/dstxfer/stake", "a", "b"), true},
+		{"receiver not allowed", PacketFilter{ReceiverAllow: []string{"c"}}, transferData("1transfer/dstxfer/stake", "a", "b"), false},
+		{"data too large", PacketFilter{MaxDataSize: 10}, transferData("1transfer/dstxfer/stake", "a", "b"), false},
+		{"not a transfer", PacketFilter{MinAmount: "100stake"}, []byte("data"), true},
+		{"not a transfer with an allow list", PacketFilter{SenderAllow: []string{"a"}}, []byte("data"), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, tc.filter.Validate())
+			err := tc.filter.Check(tc.data, prefixes...)
+			if tc.pass {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestFilterPackets(t *testing.T) {
+	src := &Chain{ChainID: "src", PathEnd: &PathEnd{PortID: "transfer", ChannelID: "srcxfer"}, logger: log.NewNopLogger()}
+	dst := &Chain{ChainID: "dst", PathEnd: &PathEnd{PortID: "transfer", ChannelID: "dstxfer"}, logger: log.NewNopLogger()}
+	pf := &PacketFilter{MinAmount: "100stake"}
+
+	small, large := transferData("99transfer/dstxfer/stake", "a", "b"), transferData("100transfer/dstxfer/stake", "a", "b")
+	packets := []relayPacket{
+		&relayMsgRecvPacket{packetData: small, seq: 1},
+		&relayMsgRecvPacket{packetData: large, seq: 2},
+		&relayMsgTimeout{packetData: small, seq: 3},
+		&relayMsgPacketAck{packetData: small, seq: 4},
+	}
+
+	var seqs []uint64
+	for _, rp := range pf.filterPackets(dst, src, packets) {
+		seqs = append(seqs, rp.Seq())
+	}
+	require.Equal(t, []uint64{2, 3, 4}, seqs)
+}
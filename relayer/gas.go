@@ -0,0 +1,201 @@
+package relayer
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	sdkCtx "github.com/cosmos/cosmos-sdk/client/context"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	authclient "github.com/cosmos/cosmos-sdk/x/auth/client"
+	chanTypes "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/types"
+)
+
+var (
+	// gasProfileWeight is how much the gas used by the latest tx moves a msg type's estimate
+	gasProfileWeight = 0.3
+
+	// gasProfileMargin pads gas limits computed from the profiles, which are
+	// less precise than a simulation of the tx
+	gasProfileMargin = 1.1
+
+	// outOfGasRetries is how many times a tx that runs out of gas is resent with a higher limit
+	outOfGasRetries = 2
+
+	// outOfGasBump multiplies the gas limit of a tx that ran out of gas
+	outOfGasBump = 1.5
+)
+
+// gasProfiles learns how much gas each msg type uses from the txs a chain commits, so that the
+// gas limit of a batch can be computed from its mix of msgs without simulating it
+type gasProfiles struct {
+	mu     sync.Mutex
+	perMsg map[string]float64 // estimated gas per msg, keyed by gasProfileKey
+}
+
+// gasProfileKey returns the key of the profile of the msg. Packet and acknowledgement
+// msgs share a msg type, so relay msgs are told apart by their Go type.
+func gasProfileKey(msg sdk.Msg) string {
+	switch msg.(type) {
+	case chanTypes.MsgPacket:
+		return "recv_packet"
+	case chanTypes.MsgAcknowledgement:
+		return "acknowledge_packet"
+	case chanTypes.MsgTimeout:
+		return "timeout_packet"
+	default:
+		return msg.Type()
+	}
+}
+
+func newGasProfiles() *gasProfiles {
+	return &gasProfiles{perMsg: make(map[string]float64)}
+}
+
+// estimate returns the gas the msgs are expected to use, or false if
+// there is no profile yet for one of their types
+func (gp *gasProfiles) estimate(msgs []sdk.Msg) (uint64, bool) {
+	if gp == nil || len(msgs) == 0 {
+		return 0, false
+	}
+
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
+
+	var total float64
+	for _, msg := range msgs {
+		gas, ok := gp.perMsg[gasProfileKey(msg)]
+		if !ok {
+			return 0, false
+		}
+		total += gas
+	}
+	return uint64(math.Ceil(total)), true
+}
+
+// observe updates the profiles of the msg types in a tx that used gasUsed. The gas is split over
+// the msgs in proportion to their current estimates, with msg types that have no estimate yet
+// getting an even share.
+func (gp *gasProfiles) observe(msgs []sdk.Msg, gasUsed uint64) {
+	if gp == nil || len(msgs) == 0 || gasUsed == 0 {
+		return
+	}
+
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
+
+	even := float64(gasUsed) / float64(len(msgs))
+	weights := make(map[string]float64)
+	var predicted float64
+	for _, msg := range msgs {
+		w, ok := gp.perMsg[gasProfileKey(msg)]
+		if !ok {
+			w = even
+		}
+		weights[gasProfileKey(msg)] = w
+		predicted += w
+	}
+
+	for key, w := range weights {
+		share := w * float64(gasUsed) / predicted
+		if gas, ok := gp.perMsg[key]; ok {
+			gp.perMsg[key] = gas*(1-gasProfileWeight) + share*gasProfileWeight
+		} else {
+			gp.perMsg[key] = share
+		}
+	}
+}
+
+// GasProfiles returns the estimated gas used by each kind of msg the chain has committed
+func (src *Chain) GasProfiles() map[string]uint64 {
+	out := make(map[string]uint64)
+	if src.gasProfiles == nil {
+		return out
+	}
+
+	src.gasProfiles.mu.Lock()
+	defer src.gasProfiles.mu.Unlock()
+	for key, gas := range src.gasProfiles.perMsg {
+		out[key] = uint64(math.Ceil(gas))
+	}
+	return out
+}
+
+// EstimateGas returns the gas limit for a tx with the msgs, including the gas adjustment. It is
+// computed from the chain's gas profiles if they cover all the msg types, otherwise it is simulated.
+func (src *Chain) EstimateGas(msgs []sdk.Msg) (uint64, error) {
+	done := src.UseSDKContext()
+	defer done()
+
+	accNum, seq, err := src.sequences.current(src, src.MustGetAddress())
+	if err != nil {
+		return 0, err
+	}
+
+	txBldr, err := src.newTxBuilder(accNum, seq)
+	if err != nil {
+		return 0, err
+	}
+
+	return src.estimateGas(txBldr, msgs)
+}
+
+// gasLimit returns the gas limit to sign a tx with the msgs with. If the gas adjustment is set it
+// is estimated. Otherwise it is computed from the gas profiles if they cover all the msg types, and
+// the configured gas is used until they do or if the gas was overridden.
+func (src *Chain) gasLimit(txBldr auth.TxBuilder, msgs []sdk.Msg) (uint64, error) {
+	if src.GasAdjustment > 0 {
+		return src.estimateGas(txBldr, msgs)
+	}
+	if src.NewGas == 0 {
+		if gas, ok := src.gasProfiles.estimate(msgs); ok {
+			return src.adjustGas(float64(gas) * gasProfileMargin), nil
+		}
+	}
+	return src.currentGas(), nil
+}
+
+func (src *Chain) estimateGas(txBldr auth.TxBuilder, msgs []sdk.Msg) (uint64, error) {
+	if gas, ok := src.gasProfiles.estimate(msgs); ok {
+		return src.adjustGas(float64(gas) * gasProfileMargin), nil
+	}
+
+	// fall back to simulating the tx, which also teaches the profiles the msg types
+	txBytes, err := txBldr.BuildTxForSim(msgs)
+	if err != nil {
+		return 0, err
+	}
+
+	simRes, _, err := authclient.CalculateGas(
+		sdkCtx.CLIContext{Client: src.RPCClient()}.QueryWithData, src.Amino.Codec, txBytes, src.GasAdjustment)
+	if err != nil {
+		return 0, err
+	}
+
+	src.gasProfiles.observe(msgs, simRes.GasUsed)
+	return src.adjustGas(float64(simRes.GasUsed)), nil
+}
+
+func (src *Chain) adjustGas(gas float64) uint64 {
+	if src.GasAdjustment > 0 {
+		gas *= src.GasAdjustment
+	}
+	return uint64(math.Ceil(gas))
+}
+
+// outOfGas returns true if the tx failed because it ran out of gas
+func outOfGas(res sdk.TxResponse, err error) bool {
+	return err == nil && res.Codespace == sdkerrors.RootCodespace && res.Code == sdkerrors.ErrOutOfGas.ABCICode()
+}
+
+// bumpGas returns the gas limit to resend a tx that ran out of gas with
+func (src *Chain) bumpGas(res sdk.TxResponse, gas uint64) uint64 {
+	if uint64(res.GasWanted) > gas {
+		gas = uint64(res.GasWanted)
+	}
+	bumped := uint64(math.Ceil(float64(gas) * outOfGasBump))
+	src.Log(fmt.Sprintf("- [%s] tx ran out of gas with a limit of %d, retrying with %d", src.ChainID, gas, bumped))
+	return bumped
+}
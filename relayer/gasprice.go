@@ -0,0 +1,189 @@
+package relayer
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// insufficientFeeRetries is how many times a tx rejected for its fee is resent with a higher gas price
+	insufficientFeeRetries = 3
+
+	// gasPriceBump multiplies the gas prices when the fee a chain requires can't be parsed from its error
+	gasPriceBump = sdk.NewDecWithPrec(125, 2)
+
+	// requiredFeeRegex matches the fee required by the ante handler in an insufficient fee error
+	requiredFeeRegex = regexp.MustCompile(`required: ([0-9a-zA-Z/.,]+)`)
+
+	// gasPriceGauge is the gas price of each denom the chains' txs are signed with, as of the
+	// chain's last tx. It is served along with the other metrics, see Chain.ServeMetrics.
+	gasPriceGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gas_price",
+		Help: "Gas price the chain's txs are signed with, by denom",
+	}, []string{"chain_id", "denom"})
+)
+
+const defaultGasPriceDecay = 10 * time.Minute
+
+// gasPricer raises a chain's gas prices above the configured floor when its txs are rejected
+// for insufficient fees, up to a ceiling. The raise decays back toward the floor over time,
+// halving every decay period, so the prices follow the chain's min fee back down.
+type gasPricer struct {
+	mu       sync.Mutex
+	raised   sdk.DecCoins
+	raisedAt time.Time
+}
+
+// price returns the gas prices given the floor, decaying the last raise
+func (gp *gasPricer) price(floor sdk.DecCoins, decay time.Duration) sdk.DecCoins {
+	if gp == nil {
+		return floor
+	}
+
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
+
+	if gp.raised.Empty() {
+		return floor
+	}
+
+	factor := math.Pow(0.5, float64(time.Since(gp.raisedAt))/float64(decay))
+	decayed := sdk.NewDecWithPrec(int64(factor*1e9), 9)
+
+	out := floor
+	for _, raised := range gp.raised {
+		base := floor.AmountOf(raised.Denom)
+		if raised.Amount.GT(base) {
+			excess := raised.Amount.Sub(base).Mul(decayed)
+			out = out.Add(sdk.NewDecCoinFromDec(raised.Denom, excess))
+		}
+	}
+	return out
+}
+
+// raise sets the gas prices of the ceiling's denoms to those needed to pay the required fee for
+// gas. If the required fee is unknown the current prices are bumped by gasPriceBump instead. The
+// prices are capped at the ceiling and raise returns false if they can't be raised any further.
+func (gp *gasPricer) raise(current, ceiling sdk.DecCoins, required sdk.Coins, gas uint64) bool {
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
+
+	raised := false
+	out := current
+	for _, limit := range ceiling {
+		price := current.AmountOf(limit.Denom)
+		target := price
+		switch {
+		case required.AmountOf(limit.Denom).IsPositive() && gas > 0:
+			target = sdk.NewDecFromInt(required.AmountOf(limit.Denom)).QuoInt64(int64(gas))
+		case required.Empty():
+			target = price.Mul(gasPriceBump)
+		}
+		if target.GT(limit.Amount) {
+			target = limit.Amount
+		}
+		if target.GT(price) {
+			out = out.Add(sdk.NewDecCoinFromDec(limit.Denom, target.Sub(price)))
+			raised = true
+		}
+	}
+
+	if raised {
+		gp.raised, gp.raisedAt = out, time.Now()
+	}
+	return raised
+}
+
+// CurrentGasPrices returns the gas prices the chain's txs are signed with. They are the configured
+// prices, or their override, unless the chain's min fee has required raising them.
+func (src *Chain) CurrentGasPrices() (sdk.DecCoins, error) {
+	floor := src.getGasPrices()
+	if src.NewGasPrices != "" {
+		var err error
+		if floor, err = sdk.ParseDecCoins(src.NewGasPrices); err != nil {
+			return nil, err
+		}
+	}
+
+	current := floor
+	if src.MaxGasPrices != "" {
+		current = src.gasPricer.price(floor, src.getGasPriceDecay())
+	}
+	for _, p := range current {
+		if price, err := strconv.ParseFloat(p.Amount.String(), 64); err == nil {
+			gasPriceGauge.WithLabelValues(src.ChainID, p.Denom).Set(price)
+		}
+	}
+	return current, nil
+}
+
+func (src *Chain) getGasPriceDecay() time.Duration {
+	if decay, err := time.ParseDuration(src.GasPriceDecay); err == nil && decay > 0 {
+		return decay
+	}
+	return defaultGasPriceDecay
+}
+
+// raiseGasPrices raises the chain's gas prices after a tx signed with the given gas limit was
+// rejected for an insufficient fee. It returns false if dynamic pricing isn't enabled or the
+// prices are already at the ceiling.
+func (src *Chain) raiseGasPrices(res sdk.TxResponse, gas uint64) bool {
+	if src.MaxGasPrices == "" || src.gasPricer == nil {
+		return false
+	}
+
+	current, err := src.CurrentGasPrices()
+	if err != nil {
+		src.Error(err)
+		return false
+	}
+
+	// max-gas-prices is checked in Init
+	ceiling, _ := sdk.ParseDecCoins(src.MaxGasPrices)
+
+	if !src.gasPricer.raise(current, ceiling, requiredFee(res), gas) {
+		src.Log(fmt.Sprintf("- [%s] tx fee is insufficient at the max gas prices %s", src.ChainID, ceiling))
+		return false
+	}
+
+	raised, _ := src.CurrentGasPrices()
+	src.Log(fmt.Sprintf("- [%s] tx fee is insufficient, raising gas prices from %s to %s", src.ChainID, current, raised))
+	return true
+}
+
+// insufficientFee returns true if the tx was rejected because its fee was too low
+func insufficientFee(res sdk.TxResponse, err error) bool {
+	return err == nil && res.Codespace == sdkerrors.RootCodespace && res.Code == sdkerrors.ErrInsufficientFee.ABCICode()
+}
+
+// requiredFee parses the fee the chain required from an insufficient fee error, if it can.
+// Decimal amounts are rounded up to whole coins.
+func requiredFee(res sdk.TxResponse) sdk.Coins {
+	match := requiredFeeRegex.FindStringSubmatch(res.RawLog)
+	if match == nil {
+		return nil
+	}
+
+	fee := sdk.NewCoins()
+	for _, s := range strings.Split(match[1], ",") {
+		coin, err := sdk.ParseCoin(s)
+		if err != nil {
+			dec, decErr := sdk.ParseDecCoin(s)
+			if decErr != nil {
+				return nil
+			}
+			coin = sdk.NewCoin(dec.Denom, dec.Amount.Ceil().TruncateInt())
+		}
+		fee = fee.Add(coin)
+	}
+	return fee
+}
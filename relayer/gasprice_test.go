@@ -0,0 +1,131 @@
+package relayer
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// insufficientFeeResponse returns the response to a tx rejected by the ante handler's fee check
+func insufficientFeeResponse(got, required string) sdk.TxResponse {
+	err := sdkerrors.Wrapf(sdkerrors.ErrInsufficientFee, "insufficient fees; got: %s required: %s", got, required)
+	codespace, code, log := sdkerrors.ABCIInfo(err, false)
+	return sdk.TxResponse{Codespace: codespace, Code: code, RawLog: log}
+}
+
+func TestRequiredFee(t *testing.T) {
+	tests := []struct {
+		name string
+		res  sdk.TxResponse
+		want string
+	}{
+		{"single coin", insufficientFeeResponse("100stake", "2000stake"), "2000stake"},
+		{"multiple coins", insufficientFeeResponse("100stake", "2000stake,15ucoin"), "2000stake,15ucoin"},
+		{"decimal amount", insufficientFeeResponse("100stake", "2000.25stake"), "2001stake"},
+		{"decimal and whole amounts", insufficientFeeResponse("", "0.5stake,15ucoin"), "1stake,15ucoin"},
+		{"ibc denom", insufficientFeeResponse("", "10transfer/ibcxfer/stake"), "10transfer/ibcxfer/stake"},
+		{"no required fee", sdk.TxResponse{RawLog: "out of gas in location: WriteFlat"}, ""},
+		{"invalid required fee", sdk.TxResponse{RawLog: "required: stake"}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fee := requiredFee(tc.res)
+			if tc.want == "" {
+				require.True(t, fee.Empty(), fee.String())
+				return
+			}
+
+			require.True(t, insufficientFee(tc.res, nil), tc.res.RawLog)
+			want, err := sdk.ParseCoins(tc.want)
+			require.NoError(t, err)
+			require.Equal(t, want, fee, tc.res.RawLog)
+		})
+	}
+}
+
+func TestGasPricerRaise(t *testing.T) {
+	decs := func(s string) sdk.DecCoins {
+		coins, err := sdk.ParseDecCoins(s)
+		if err != nil {
+			panic(err)
+		}
+		return coins
+	}
+	tests := []struct {
+		name     string
+		current  string
+		ceiling  string
+		required string
+		gas      uint64
+		raised   bool
+		want     string
+	}{
+		{"to the required fee", "0.01stake", "0.1stake", "2000stake", 100000, true, "0.02stake"},
+		{"clamped to the ceiling", "0.01stake", "0.1stake", "20000stake", 100000, true, "0.1stake"},
+		{"at the ceiling", "0.1stake", "0.1stake", "20000stake", 100000, false, "0.1stake"},
+		{"already enough", "0.05stake", "0.1stake", "2000stake", 100000, false, "0.05stake"},
+		{"multiple denoms", "0.01stake", "0.1stake,0.1ucoin", "2000stake,500ucoin", 100000, true, "0.02stake,0.005ucoin"},
+		{"only denoms with a ceiling", "0.01stake", "0.1stake", "500ucoin", 100000, false, "0.01stake"},
+		{"unknown fee bumps by a quarter", "0.01stake", "0.1stake", "", 100000, true, "0.0125stake"},
+		{"bump clamped to the ceiling", "0.09stake", "0.1stake", "", 100000, true, "0.1stake"},
+		{"bump from zero", "0.01ucoin", "0.1stake", "", 100000, false, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			required, err := sdk.ParseCoins(tc.required)
+			require.NoError(t, err)
+
+			gp := &gasPricer{}
+			require.Equal(t, tc.raised, gp.raise(decs(tc.current), decs(tc.ceiling), required, tc.gas))
+			if tc.raised {
+				require.Equal(t, decs(tc.want), gp.raised)
+			} else {
+				require.True(t, gp.raised.Empty())
+			}
+		})
+	}
+}
+
+func TestGasPricerDecay(t *testing.T) {
+	floor, err := sdk.ParseDecCoins("0.01stake")
+	require.NoError(t, err)
+	raised, err := sdk.ParseDecCoins("0.09stake,0.5ucoin")
+	require.NoError(t, err)
+
+	const decay = 10 * time.Minute
+	tests := []struct {
+		since       time.Duration
+		stake, coin float64
+	}{
+		{0, 0.09, 0.5},
+		{decay, 0.05, 0.25},
+		{2 * decay, 0.03, 0.125},
+		{10 * decay, 0.01 + 0.08/1024, 0.5 / 1024},
+	}
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprint(tc.since), func(t *testing.T) {
+			gp := &gasPricer{raised: raised, raisedAt: time.Now().Add(-tc.since)}
+			price := gp.price(floor, decay)
+			require.InDelta(t, tc.stake, decFloat(price.AmountOf("stake")), 1e-6)
+			require.InDelta(t, tc.coin, decFloat(price.AmountOf("ucoin")), 1e-6)
+		})
+	}
+
+	require.Equal(t, floor, (&gasPricer{}).price(floor, decay))
+	require.Equal(t, floor, (*gasPricer)(nil).price(floor, decay))
+}
+
+func decFloat(d sdk.Dec) float64 {
+	var f float64
+	if _, err := fmt.Sscan(d.String(), &f); err != nil {
+		panic(err)
+	}
+	return f
+}
@@ -0,0 +1,229 @@
+package relayer
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	keys "github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+const (
+	gunTickInterval   = 100 * time.Millisecond
+	gunReportInterval = 10 * time.Second
+
+	// gunBehindRatio is the share of its target rate below which an open-loop leg is reported
+	// to have fallen behind
+	gunBehindRatio = 0.95
+)
+
+// sendGunBatch broadcasts an open-loop batch signed by the key without waiting for it to be committed
+var sendGunBatch = func(c *Chain, msgs []sdk.Msg, info keys.Info) (sdk.TxResponse, error) {
+	return c.sendMsgsWithKey(msgs, info, c.BroadcastTxSync, false)
+}
+
+// tokenBucket holds up to capacity tokens, refilled at rate tokens per second. Tokens that
+// would overflow it are counted as dropped.
+type tokenBucket struct {
+	rate, capacity  float64
+	tokens, dropped float64
+	last            time.Time
+}
+
+func newTokenBucket(rate float64, capacity int, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, capacity: float64(capacity), last: now}
+}
+
+// refill adds the tokens accrued since the last refill and returns the whole tokens available
+func (tb *tokenBucket) refill(now time.Time) int {
+	tb.tokens += now.Sub(tb.last).Seconds() * tb.rate
+	tb.last = now
+	if tb.tokens > tb.capacity {
+		tb.dropped += tb.tokens - tb.capacity
+		tb.tokens = tb.capacity
+	}
+	return int(tb.tokens)
+}
+
+func (tb *tokenBucket) take(n int) {
+	tb.tokens -= float64(n)
+}
+
+// gunSigner broadcasts the open-loop batches of one of the sending chain's keys, a tx at a time
+type gunSigner struct {
+	info    keys.Info
+	busy    int32
+	batches chan gunBatch
+}
+
+// gunLoop is the open-loop state of a leg. The bucket and the last* fields are only used by
+// the goroutine dispatching the batches.
+type gunLoop struct {
+	leg     *gunLeg
+	bucket  *tokenBucket
+	signers []*gunSigner
+
+	sent, failed, rejected int64
+
+	reported                         time.Time
+	lastSent, lastRejected, lastDrop int64
+}
+
+// gunOpenLoop sends the phase's transfers at its TPS on every leg without waiting for them to be
+// committed. The msgs of each leg's token bucket are handed to whichever signers of the sending
+// chain are idle, so the rate doesn't depend on how long the chain takes to accept a tx. Every
+// gunReportInterval it logs the rate each leg achieved, and why if it fell behind the target:
+// txs rejected by the mempool, or msgs dropped from the bucket while all the signers were busy.
+func (src *Chain) gunOpenLoop(legs []*gunLeg, p *GunPhase, rnd *rand.Rand) (sent, failed int64, err error) {
+	var (
+		start = time.Now()
+		end   = start.Add(p.duration)
+		wg    sync.WaitGroup
+		loops = make([]*gunLoop, 0, len(legs))
+	)
+
+	defer func() {
+		for _, lp := range loops {
+			for _, s := range lp.signers {
+				close(s.batches)
+			}
+		}
+		wg.Wait()
+		for _, lp := range loops {
+			// the whole phase, as the msgs still in flight at the last report have since been sent
+			lp.logRate(p, time.Since(start), lp.sent, lp.rejected, int64(lp.bucket.dropped))
+			sent += lp.sent
+			failed += lp.failed
+		}
+	}()
+
+	for _, l := range legs {
+		lp := &gunLoop{leg: l, bucket: newTokenBucket(p.TPS, p.Burst, start), reported: start}
+		for _, name := range l.from.Signers() {
+			info, err := l.from.Keybase.Key(name)
+			if err != nil {
+				return 0, 0, err
+			}
+			s := &gunSigner{info: info, batches: make(chan gunBatch, 1)}
+			lp.signers = append(lp.signers, s)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				lp.run(s)
+			}()
+		}
+		loops = append(loops, lp)
+	}
+
+	ticker := time.NewTicker(gunTickInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		if !now.Before(end) {
+			return 0, 0, nil
+		}
+		for _, lp := range loops {
+			if err = lp.dispatch(p, rnd, now); err != nil {
+				return 0, 0, err
+			}
+			if now.Sub(lp.reported) >= gunReportInterval {
+				lp.report(p, now)
+			}
+		}
+	}
+	return 0, 0, nil
+}
+
+// dispatch splits the whole tokens in the bucket over the idle signers as batches of transfers
+func (lp *gunLoop) dispatch(p *GunPhase, rnd *rand.Rand, now time.Time) error {
+	n := lp.bucket.refill(now)
+	if n == 0 {
+		return nil
+	}
+
+	var idle []*gunSigner
+	for _, s := range lp.signers {
+		if atomic.LoadInt32(&s.busy) == 0 {
+			idle = append(idle, s)
+		}
+	}
+
+	for i, s := range idle {
+		// spread the remainder over the first signers
+		count := n / len(idle)
+		if i < n%len(idle) {
+			count++
+		}
+		if count == 0 {
+			continue
+		}
+
+		b, err := lp.leg.batch(count, p, rnd, s.info.GetAddress(), time.Second)
+		if err != nil {
+			return err
+		}
+		lp.bucket.take(count)
+		atomic.StoreInt32(&s.busy, 1)
+		s.batches <- b
+	}
+	return nil
+}
+
+// run broadcasts the signer's batches until its channel is closed
+func (lp *gunLoop) run(s *gunSigner) {
+	from := lp.leg.from
+	for b := range s.batches {
+		n := int64(len(b.msgs))
+		// sequences are handed out locally, so transfers can be sent
+		// without waiting for the previous ones to be committed
+		res, err := sendGunBatch(from, b.msgs, s.info)
+		switch {
+		case mempoolRejected(res, err):
+			atomic.AddInt64(&lp.rejected, n)
+			fallthrough
+		case err != nil || res.Code != 0:
+			atomic.AddInt64(&lp.failed, n)
+			from.LogFailedTx(res, err, b.msgs)
+		default:
+			atomic.AddInt64(&lp.sent, n)
+			from.LogSuccessTx(res, b.msgs)
+		}
+		atomic.StoreInt32(&s.busy, 0)
+	}
+}
+
+// report logs the rate the leg achieved since its last report
+func (lp *gunLoop) report(p *GunPhase, now time.Time) {
+	sent, rejected, dropped := atomic.LoadInt64(&lp.sent), atomic.LoadInt64(&lp.rejected), int64(lp.bucket.dropped)
+	lp.logRate(p, now.Sub(lp.reported), sent-lp.lastSent, rejected-lp.lastRejected, dropped-lp.lastDrop)
+	lp.reported, lp.lastSent, lp.lastRejected, lp.lastDrop = now, sent, rejected, dropped
+}
+
+// logRate logs the rate the leg achieved over the elapsed time, and why if it fell behind the target
+func (lp *gunLoop) logRate(p *GunPhase, elapsed time.Duration, sent, rejected, dropped int64) {
+	if elapsed < time.Second {
+		return
+	}
+
+	from, to := lp.leg.from, lp.leg.to
+	rate := float64(sent) / elapsed.Seconds()
+	if rate < gunBehindRatio*p.TPS {
+		from.Log(fmt.Sprintf("! [%s] gun phase %s is behind its target rate to %s: %.1f of %g tps, "+
+			"%d msgs rejected by the mempool, %d dropped while all %d signers were busy", from.ChainID, p.Name,
+			to.ChainID, rate, p.TPS, rejected, dropped, len(lp.signers)))
+		return
+	}
+	from.Log(fmt.Sprintf("- [%s] gun phase %s: %.1f of %g tps to %s", from.ChainID, p.Name, rate, p.TPS, to.ChainID))
+}
+
+// mempoolRejected returns true if the tx was rejected because the node's mempool is full
+func mempoolRejected(res sdk.TxResponse, err error) bool {
+	if err != nil {
+		return strings.Contains(err.Error(), "mempool is full")
+	}
+	return res.Codespace == sdkerrors.RootCodespace && res.Code == sdkerrors.ErrMempoolIsFull.ABCICode()
+}
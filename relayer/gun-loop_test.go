@@ -0,0 +1,226 @@
+package relayer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	keys "github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Unix(0, 0)
+	type step struct {
+		at      time.Duration // since start
+		take    int
+		tokens  int
+		dropped float64
+	}
+	tests := []struct {
+		name     string
+		rate     float64
+		capacity int
+		steps    []step
+	}{
+		{"empty at start", 10, 5, []step{
+			{0, 0, 0, 0},
+		}},
+		{"fills at the rate", 10, 5, []step{
+			{100 * time.Millisecond, 0, 1, 0},
+			{300 * time.Millisecond, 0, 3, 0},
+		}},
+		{"keeps fractions", 2.5, 5, []step{
+			{300 * time.Millisecond, 0, 0, 0},
+			{time.Second, 0, 2, 0},
+			{2 * time.Second, 0, 5, 0},
+		}},
+		{"taking leaves the rest", 10, 5, []step{
+			{400 * time.Millisecond, 3, 4, 0},
+			{600 * time.Millisecond, 0, 3, 0},
+		}},
+		{"overflow is dropped", 10, 5, []step{
+			{time.Second, 0, 5, 5},
+			{2 * time.Second, 0, 5, 15},
+		}},
+		{"taking makes room again", 10, 5, []step{
+			{time.Second, 5, 5, 5},
+			{1300 * time.Millisecond, 0, 3, 5},
+			{1800 * time.Millisecond, 0, 5, 8},
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tb := newTokenBucket(tc.rate, tc.capacity, start)
+			for i, s := range tc.steps {
+				n := tb.refill(start.Add(s.at))
+				require.Equal(t, s.tokens, n, "step %d", i)
+				require.InDelta(t, s.dropped, tb.dropped, 1e-9, "step %d", i)
+				tb.take(s.take)
+			}
+		})
+	}
+}
+
+func TestMempoolRejected(t *testing.T) {
+	full := sdkerrors.ErrMempoolIsFull
+	tests := []struct {
+		name string
+		res  sdk.TxResponse
+		err  error
+		want bool
+	}{
+		{"accepted", sdk.TxResponse{}, nil, false},
+		{"mempool is full", sdk.TxResponse{Codespace: full.Codespace(), Code: full.ABCICode()}, nil, true},
+		{"other code", sdk.TxResponse{Codespace: full.Codespace(), Code: sdkerrors.ErrOutOfGas.ABCICode()}, nil, false},
+		{"same code in another codespace", sdk.TxResponse{Codespace: "ibc", Code: full.ABCICode()}, nil, false},
+		{"broadcast error", sdk.TxResponse{}, fmt.Errorf("mempool is full: 5000 txs"), true},
+		{"other broadcast error", sdk.TxResponse{}, fmt.Errorf("connection refused"), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, mempoolRejected(tc.res, tc.err))
+		})
+	}
+}
+
+// gunTestChain returns a chain signing with keys of the given names, which are only good for
+// their addresses
+func gunTestChain(t *testing.T, chainID, channelID string, w io.Writer, names ...string) *Chain {
+	kb := keys.NewInMemory()
+	for _, name := range names {
+		_, err := kb.SavePubKey(name, secp256k1.GenPrivKey().PubKey(), hd.Secp256k1Type)
+		require.NoError(t, err)
+	}
+	return &Chain{
+		ChainID:      chainID,
+		Key:          names[0],
+		Keys:         names,
+		KeySelection: "round-robin",
+		Keybase:      kb,
+		PathEnd:      &PathEnd{ChainID: chainID, PortID: "transfer", ChannelID: channelID},
+		logger:       log.NewTMLogger(log.NewSyncWriter(w)),
+	}
+}
+
+func gunTestLeg(from, to *Chain, p *GunPhase) *gunLeg {
+	// the timeout height isn't queried while it is fresh
+	return &gunLeg{from: from, to: to, receiver: "cosmos1receiver", denoms: p.Denoms,
+		dstHeight: 100, dstUpdated: time.Now().Add(time.Hour)}
+}
+
+func gunTestPhase(t *testing.T, tps float64, duration string) *GunPhase {
+	p := &GunPhase{
+		Name:     "test",
+		TPS:      tps,
+		Duration: duration,
+		OpenLoop: true,
+		Amounts:  []GunAmount{{Min: 1, Max: 10}},
+		Denoms:   []GunDenom{{Denom: "stake", Source: true}},
+	}
+	require.NoError(t, p.validate())
+	return p
+}
+
+func TestGunLoopDispatch(t *testing.T) {
+	src := gunTestChain(t, "src", "srcxfer", ioutil.Discard, "a", "b", "c")
+	dst := gunTestChain(t, "dst", "dstxfer", ioutil.Discard, "a")
+	p := gunTestPhase(t, 10, "1m")
+
+	start := time.Unix(0, 0)
+	lp := &gunLoop{leg: gunTestLeg(src, dst, p), bucket: newTokenBucket(p.TPS, p.Burst, start)}
+	for _, name := range src.Signers() {
+		info, err := src.Keybase.Key(name)
+		require.NoError(t, err)
+		lp.signers = append(lp.signers, &gunSigner{info: info, batches: make(chan gunBatch, 1)})
+	}
+	rnd := rand.New(rand.NewSource(1))
+
+	// dispatch hands the batches to the idle signers and returns the msgs each one got
+	dispatch := func(at time.Duration) []int {
+		require.NoError(t, lp.dispatch(p, rnd, start.Add(at)))
+		counts := make([]int, len(lp.signers))
+		for i, s := range lp.signers {
+			select {
+			case b := <-s.batches:
+				counts[i] = len(b.msgs)
+				for _, msg := range b.msgs {
+					require.Equal(t, []sdk.AccAddress{s.info.GetAddress()}, msg.GetSigners())
+				}
+			default:
+			}
+		}
+		return counts
+	}
+
+	// a second's worth of msgs is spread over the idle signers, the remainder on the first ones
+	require.Equal(t, []int{4, 3, 3}, dispatch(time.Second))
+	for _, s := range lp.signers {
+		require.Equal(t, int32(1), atomic.LoadInt32(&s.busy))
+	}
+
+	// busy signers are skipped
+	atomic.StoreInt32(&lp.signers[1].busy, 0)
+	require.Equal(t, []int{0, 5, 0}, dispatch(1500*time.Millisecond))
+
+	// while all the signers are busy the msgs over the bucket's capacity are dropped
+	require.Equal(t, []int{0, 0, 0}, dispatch(3*time.Second))
+	require.InDelta(t, 5, lp.bucket.dropped, 1e-9)
+
+	// and the bucket is emptied by the first idle signer
+	atomic.StoreInt32(&lp.signers[2].busy, 0)
+	require.Equal(t, []int{0, 0, 10}, dispatch(3*time.Second))
+}
+
+func TestGunOpenLoop(t *testing.T) {
+	var logs bytes.Buffer
+	src := gunTestChain(t, "src", "srcxfer", &logs, "a", "b")
+	dst := gunTestChain(t, "dst", "dstxfer", ioutil.Discard, "a")
+	p := gunTestPhase(t, 20, "1s")
+
+	// the txs signed by b are rejected by the mempool
+	var (
+		mu   sync.Mutex
+		sent = map[string]int{}
+	)
+	defer func(send func(*Chain, []sdk.Msg, keys.Info) (sdk.TxResponse, error)) { sendGunBatch = send }(sendGunBatch)
+	sendGunBatch = func(c *Chain, msgs []sdk.Msg, info keys.Info) (sdk.TxResponse, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		sent[info.GetName()] += len(msgs)
+		if info.GetName() == "b" {
+			full := sdkerrors.ErrMempoolIsFull
+			return sdk.TxResponse{Codespace: full.Codespace(), Code: full.ABCICode()}, nil
+		}
+		return sdk.TxResponse{Height: 1}, nil
+	}
+
+	ok, failed, err := src.gunOpenLoop([]*gunLeg{gunTestLeg(src, dst, p)}, p, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+
+	// the bucket starts empty and the last tick may come a little before the second is up
+	mu.Lock()
+	defer mu.Unlock()
+	require.InDelta(t, p.TPS, ok+failed, 3)
+	require.Equal(t, int64(sent["a"]), ok)
+	require.Equal(t, int64(sent["b"]), failed)
+	require.NotZero(t, sent["a"])
+	require.NotZero(t, sent["b"])
+
+	// half the msgs were rejected, so the leg fell behind its target rate
+	require.Contains(t, logs.String(), "gun phase test is behind its target rate to dst")
+	require.Contains(t, logs.String(), fmt.Sprintf("%d msgs rejected by the mempool", failed))
+}
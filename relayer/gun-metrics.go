@@ -0,0 +1,69 @@
+package relayer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The metrics of the transfers Gun broadcasts, labeled with the sending chain and its channel.
+// They are served when a metrics port is given, see Chain.ServeMetrics.
+var (
+	gunMsgsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gun_msgs_sent_total",
+		Help: "Transfer msgs broadcast by gun",
+	}, []string{"chain_id", "channel_id"})
+
+	gunTxsAccepted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gun_txs_accepted_total",
+		Help: "Transfer txs broadcast by gun that were accepted by the node",
+	}, []string{"chain_id", "channel_id"})
+
+	gunTxsRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gun_txs_rejected_total",
+		Help: "Transfer txs broadcast by gun that were rejected, by codespace/code or broadcast error",
+	}, []string{"chain_id", "channel_id", "code"})
+
+	gunBacklog = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gun_backlog_msgs",
+		Help: "Transfer msgs accepted by the node that haven't been committed yet, while gun runs",
+	}, []string{"chain_id", "channel_id"})
+
+	gunBroadcastLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gun_broadcast_latency_seconds",
+		Help:    "Time taken to broadcast a transfer tx, including the wait for it to be committed if gun waits",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 14),
+	}, []string{"chain_id", "channel_id"})
+
+	gunMetricsOnce sync.Once
+)
+
+// registerGunMetrics registers Gun's metrics with prometheus, once per process
+func registerGunMetrics() {
+	gunMetricsOnce.Do(func() {
+		prometheus.MustRegister(gunMsgsSent, gunTxsAccepted, gunTxsRejected, gunBacklog, gunBroadcastLatency)
+	})
+}
+
+// observeGunBroadcast records the outcome of a transfer tx broadcast from the chain. reason is
+// empty if the tx was accepted. backlog is false if the tx is already known to be committed.
+func observeGunBroadcast(from *Chain, msgs int, reason string, sentAt time.Time, backlog bool) {
+	chainID, channelID := from.ChainID, from.PathEnd.ChannelID
+	gunMsgsSent.WithLabelValues(chainID, channelID).Add(float64(msgs))
+	gunBroadcastLatency.WithLabelValues(chainID, channelID).Observe(time.Since(sentAt).Seconds())
+	if reason != "" {
+		gunTxsRejected.WithLabelValues(chainID, channelID, reason).Inc()
+		return
+	}
+	gunTxsAccepted.WithLabelValues(chainID, channelID).Inc()
+	if backlog {
+		gunBacklog.WithLabelValues(chainID, channelID).Add(float64(msgs))
+	}
+}
+
+// drainGunBacklog removes the msgs of a transfer tx from the chain's backlog, once it was
+// committed or the tracker stops waiting for it
+func drainGunBacklog(from *Chain, msgs int) {
+	gunBacklog.WithLabelValues(from.ChainID, from.PathEnd.ChannelID).Sub(float64(msgs))
+}
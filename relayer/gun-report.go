@@ -0,0 +1,563 @@
+package relayer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	xferTypes "github.com/cosmos/cosmos-sdk/x/ibc/20-transfer/types"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// GunPacket is the progress of a packet sent by Gun. The latencies are in milliseconds from the
+// time its transfer was broadcast, and are zero until the packet got that far.
+type GunPacket struct {
+	Phase      string    `json:"phase"`
+	SrcChainID string    `json:"src-chain-id"`
+	DstChainID string    `json:"dst-chain-id"`
+	SrcChannel string    `json:"src-channel"`
+	Sequence   uint64    `json:"sequence"`
+	SendTx     string    `json:"send-tx"`
+	SendHeight int64     `json:"send-height"`
+	RecvHeight int64     `json:"recv-height,omitempty"`
+	AckHeight  int64     `json:"ack-height,omitempty"`
+	SentAt     time.Time `json:"sent-at"`
+	CommitMs   float64   `json:"commit-ms"`
+	RecvMs     float64   `json:"recv-ms,omitempty"`
+	AckMs      float64   `json:"ack-ms,omitempty"`
+}
+
+// GunLatency summarizes the latencies of the packets that got to a stage, in milliseconds
+type GunLatency struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+}
+
+// GunReport is the outcome of a Gun run. Failures counts the transfers that weren't committed
+// by ABCI code, as codespace/code, or by the reason they weren't.
+type GunReport struct {
+	Sent      int            `json:"sent"`
+	Committed int            `json:"committed"`
+	Received  int            `json:"received"`
+	Acked     int            `json:"acknowledged"`
+	Failures  map[string]int `json:"failures"`
+	Commit    GunLatency     `json:"commit"`
+	Recv      GunLatency     `json:"recv"`
+	Ack       GunLatency     `json:"ack"`
+	Packets   []GunPacket    `json:"packets"`
+}
+
+// gunPacketKey identifies a packet by its source end and sequence
+type gunPacketKey struct {
+	port, channel string
+	seq           uint64
+}
+
+// gunBroadcast is a transfer tx that Gun broadcast
+type gunBroadcast struct {
+	phase    string
+	from, to *Chain
+	msgs     int
+	sentAt   time.Time
+}
+
+// gunTxEvent is a tx that was committed on one of the chains
+type gunTxEvent struct {
+	height    int64
+	at        time.Time
+	code      uint32
+	codespace string
+	sends     []gunPacketKey
+}
+
+// gunObservation is a packet's recv or ack as seen in a tx event
+type gunObservation struct {
+	height int64
+	at     time.Time
+}
+
+// gunTracker follows the packets Gun sends through their recv and ack. Gun's transfer txs are
+// recorded by hash as they are broadcast, and tied to the packets they sent through the
+// send_packet events of the tx. Those are tied to their recv and ack through the recv_packet
+// and acknowledge_packet events, by the source channel and sequence of the packet. Events that
+// can't be tied to Gun's transfers yet are kept for the confirm timeout and then pruned, so
+// other traffic on the chains doesn't accumulate.
+type gunTracker struct {
+	src, dst *Chain
+	subs     []*eventSubscription
+	done     chan struct{}
+	wg       sync.WaitGroup
+
+	mu         sync.Mutex
+	phase      string
+	broadcasts map[string]gunBroadcast
+	txs        map[string]gunTxEvent
+	packets    map[gunPacketKey]bool
+	recvs      map[gunPacketKey]gunObservation
+	acks       map[gunPacketKey]gunObservation
+	failures   map[string]int
+}
+
+// startGunTracker subscribes to the events of both chains and starts recording the outcome
+// of the transfers they broadcast
+func startGunTracker(src, dst *Chain) (*gunTracker, error) {
+	t := newGunTracker(src, dst)
+
+	// the observers are set before any goroutine sending the chains' txs is started
+	registerGunMetrics()
+	src.txObserver = t.observer(src, dst)
+	dst.txObserver = t.observer(dst, src)
+
+	for _, c := range []*Chain{src, dst} {
+		sub, err := subscribeEvents(c)
+		if err != nil {
+			t.stop()
+			return nil, err
+		}
+		t.subs = append(t.subs, sub)
+
+		t.wg.Add(1)
+		go t.listen(sub)
+	}
+	return t, nil
+}
+
+func newGunTracker(src, dst *Chain) *gunTracker {
+	return &gunTracker{
+		src:        src,
+		dst:        dst,
+		done:       make(chan struct{}),
+		broadcasts: make(map[string]gunBroadcast),
+		txs:        make(map[string]gunTxEvent),
+		packets:    make(map[gunPacketKey]bool),
+		recvs:      make(map[gunPacketKey]gunObservation),
+		acks:       make(map[gunPacketKey]gunObservation),
+		failures:   make(map[string]int),
+	}
+}
+
+// stop stops recording transfers and unsubscribes from the chains. The goroutines sending the
+// chains' txs must have returned.
+func (t *gunTracker) stop() {
+	close(t.done)
+	t.wg.Wait()
+	t.src.txObserver, t.dst.txObserver = nil, nil
+	// the subscriptions are only replaced by the listeners, which have returned
+	for _, sub := range t.subs {
+		sub.cancel()
+	}
+
+	// the transfers that weren't seen committed by now were dropped, or won't be seen anymore
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for hash, b := range t.broadcasts {
+		if _, seen := t.txs[hash]; !seen {
+			drainGunBacklog(b.from, b.msgs)
+		}
+	}
+}
+
+// setPhase sets the phase the transfers broadcast from now on are recorded under
+func (t *gunTracker) setPhase(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phase = name
+}
+
+// observer returns the function the chain calls with the outcome of each tx it broadcasts
+func (t *gunTracker) observer(from, to *Chain) func(sdk.TxResponse, error, []sdk.Msg, time.Time) {
+	return func(res sdk.TxResponse, err error, msgs []sdk.Msg, sentAt time.Time) {
+		if len(msgs) == 0 {
+			return
+		}
+		if _, ok := msgs[0].(xferTypes.MsgTransfer); !ok {
+			return
+		}
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		var reason string
+		switch {
+		case err != nil:
+			reason = "broadcast error"
+		case res.Code != 0:
+			reason = fmt.Sprintf("%s/%d", res.Codespace, res.Code)
+		}
+		hash := strings.ToUpper(res.TxHash)
+		tx, committed := t.txs[hash]
+		observeGunBroadcast(from, len(msgs), reason, sentAt, !committed)
+		if reason != "" {
+			t.failures[reason] += len(msgs)
+			return
+		}
+		t.broadcasts[hash] = gunBroadcast{phase: t.phase, from: from, to: to, msgs: len(msgs), sentAt: sentAt}
+		if committed {
+			t.tie(from, tx)
+		}
+	}
+}
+
+// listen records the send_packet, recv_packet and acknowledge_packet events of the chain's tx
+// events until the tracker is stopped. A subscription that is closed or stops delivering blocks
+// is reconnected, and the commits of the chain's transfers missed meanwhile are queried instead.
+func (t *gunTracker) listen(sub *eventSubscription) {
+	defer t.wg.Done()
+	ticker := time.NewTicker(staleSubscriptionTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		lost := false
+		select {
+		case <-t.done:
+			return
+		case ev, ok := <-sub.txs:
+			if !ok {
+				lost = true
+				break
+			}
+			t.record(ev, time.Now())
+		case _, ok := <-sub.blocks:
+			if !ok {
+				lost = true
+				break
+			}
+			sub.lastBlock = time.Now()
+		case now := <-ticker.C:
+			lost = sub.stale()
+			t.prune(now)
+		}
+
+		if lost {
+			if !sub.resubscribe(t.done) {
+				return
+			}
+			t.backfill(sub.chain)
+		}
+	}
+}
+
+func (t *gunTracker) record(ev ctypes.ResultEvent, at time.Time) {
+	hashes, heights := ev.Events["tx.hash"], ev.Events["tx.height"]
+	if len(hashes) == 0 || len(heights) == 0 {
+		return
+	}
+	height, err := strconv.ParseInt(heights[0], 10, 64)
+	if err != nil {
+		return
+	}
+
+	tx := gunTxEvent{height: height, at: at, sends: eventPackets(ev.Events, "send_packet")}
+	if data, ok := ev.Data.(tmtypes.EventDataTx); ok {
+		tx.code, tx.codespace = data.Result.Code, data.Result.Codespace
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(tx.sends) > 0 || tx.code != 0 {
+		t.commit(strings.ToUpper(hashes[0]), tx)
+	}
+	// recvs and acks are kept whether or not their packets are known to be Gun's yet, as
+	// they may be seen before the send of a tx that waited to be committed is tied to it
+	for _, k := range eventPackets(ev.Events, "recv_packet") {
+		t.recvs[k] = gunObservation{height: height, at: at}
+	}
+	for _, k := range eventPackets(ev.Events, "acknowledge_packet") {
+		t.acks[k] = gunObservation{height: height, at: at}
+	}
+}
+
+// commit records a tx that was committed. It is kept whether or not it is known to be Gun's yet,
+// as the event may arrive before the broadcast of a tx that waited to be committed returns.
+func (t *gunTracker) commit(hash string, tx gunTxEvent) {
+	if _, seen := t.txs[hash]; seen {
+		return
+	}
+	t.txs[hash] = tx
+	if b, ok := t.broadcasts[hash]; ok {
+		drainGunBacklog(b.from, b.msgs)
+		t.tie(b.from, tx)
+	}
+}
+
+// tie marks the packets one of Gun's txs sent from the chain's end of the path as Gun's
+func (t *gunTracker) tie(from *Chain, tx gunTxEvent) {
+	for _, k := range tx.sends {
+		if k.port == from.PathEnd.PortID && k.channel == from.PathEnd.ChannelID {
+			t.packets[k] = true
+		}
+	}
+}
+
+// prune drops the txs, recvs and acks older than the confirm timeout that aren't Gun's
+func (t *gunTracker) prune(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cutoff := now.Add(-t.src.GetConfirmTimeout())
+	for hash, tx := range t.txs {
+		if _, ok := t.broadcasts[hash]; !ok && tx.at.Before(cutoff) {
+			delete(t.txs, hash)
+		}
+	}
+	for _, obs := range []map[gunPacketKey]gunObservation{t.recvs, t.acks} {
+		for k, o := range obs {
+			if !t.packets[k] && o.at.Before(cutoff) {
+				delete(obs, k)
+			}
+		}
+	}
+}
+
+// backfill queries the chain for the transfers broadcast from it whose commit wasn't seen, e.g.
+// while its subscription was being reconnected. The recvs and acks missed aren't recovered.
+func (t *gunTracker) backfill(c *Chain) {
+	t.mu.Lock()
+	var hashes []string
+	for hash, b := range t.broadcasts {
+		if _, seen := t.txs[hash]; !seen && b.from.ChainID == c.ChainID {
+			hashes = append(hashes, hash)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, hash := range hashes {
+		res, err := c.QueryTx(hash)
+		if err != nil {
+			// not committed yet, its event is still to come
+			continue
+		}
+
+		tx := gunTxEvent{height: res.Height, at: time.Now(), code: res.Code, codespace: res.Codespace}
+		if ts, err := time.Parse(time.RFC3339, res.Timestamp); err == nil {
+			tx.at = ts
+		}
+		for _, l := range res.Logs {
+			for _, e := range l.Events {
+				if e.Type == "send_packet" {
+					tx.sends = append(tx.sends, logPacket(e))
+				}
+			}
+		}
+
+		t.mu.Lock()
+		t.commit(hash, tx)
+		t.mu.Unlock()
+	}
+}
+
+// logPacket returns the source end and sequence of the packet in a tx log event
+func logPacket(e sdk.StringEvent) (k gunPacketKey) {
+	for _, a := range e.Attributes {
+		switch a.Key {
+		case "packet_src_port":
+			k.port = a.Value
+		case "packet_src_channel":
+			k.channel = a.Value
+		case "packet_sequence":
+			k.seq, _ = strconv.ParseUint(a.Value, 10, 64)
+		}
+	}
+	return k
+}
+
+// eventPackets returns the source ends and sequences of the packets in the events of the given type
+func eventPackets(events map[string][]string, eventType string) (keys []gunPacketKey) {
+	seqs := events[eventType+".packet_sequence"]
+	ports, chans := events[eventType+".packet_src_port"], events[eventType+".packet_src_channel"]
+	for i := range seqs {
+		if i >= len(ports) || i >= len(chans) {
+			break
+		}
+		seq, err := strconv.ParseUint(seqs[i], 10, 64)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, gunPacketKey{port: ports[i], channel: chans[i], seq: seq})
+	}
+	return keys
+}
+
+// finish waits up to the confirm timeout for the transfers to be committed, and then up to
+// settle for their packets to be received and acknowledged, and returns the report of the run
+func (t *gunTracker) finish(settle time.Duration) *GunReport {
+	commitDeadline := time.Now().Add(t.src.GetConfirmTimeout())
+	for {
+		rep := t.report(false)
+		if rep.Committed+sumFailures(rep.Failures) >= rep.Sent || time.Now().After(commitDeadline) {
+			break
+		}
+		time.Sleep(confirmPollInterval)
+	}
+
+	settleDeadline := time.Now().Add(settle)
+	for {
+		rep := t.report(false)
+		if rep.Acked >= rep.Committed || !time.Now().Before(settleDeadline) {
+			return t.report(true)
+		}
+		time.Sleep(confirmPollInterval)
+	}
+}
+
+// report joins the transfers broadcast with the events seen so far. When final, transfers that
+// weren't seen committed count as failures.
+func (t *gunTracker) report(final bool) *GunReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rep := &GunReport{Failures: make(map[string]int), Packets: []GunPacket{}}
+	for k, n := range t.failures {
+		rep.Failures[k] = n
+		rep.Sent += n
+	}
+
+	var commits, recvs, acks []float64
+	for hash, b := range t.broadcasts {
+		rep.Sent += b.msgs
+		tx, ok := t.txs[hash]
+		switch {
+		case !ok:
+			if final {
+				rep.Failures["not committed"] += b.msgs
+			}
+			continue
+		case tx.code != 0:
+			rep.Failures[fmt.Sprintf("%s/%d", tx.codespace, tx.code)] += b.msgs
+			continue
+		}
+
+		for _, k := range tx.sends {
+			if k.port != b.from.PathEnd.PortID || k.channel != b.from.PathEnd.ChannelID {
+				continue
+			}
+			rep.Committed++
+			p := GunPacket{
+				Phase:      b.phase,
+				SrcChainID: b.from.ChainID,
+				DstChainID: b.to.ChainID,
+				SrcChannel: k.channel,
+				Sequence:   k.seq,
+				SendTx:     hash,
+				SendHeight: tx.height,
+				SentAt:     b.sentAt,
+				CommitMs:   sinceMs(b.sentAt, tx.at),
+			}
+			commits = append(commits, p.CommitMs)
+			if recv, ok := t.recvs[k]; ok {
+				rep.Received++
+				p.RecvHeight, p.RecvMs = recv.height, sinceMs(b.sentAt, recv.at)
+				recvs = append(recvs, p.RecvMs)
+			}
+			if ack, ok := t.acks[k]; ok {
+				rep.Acked++
+				p.AckHeight, p.AckMs = ack.height, sinceMs(b.sentAt, ack.at)
+				acks = append(acks, p.AckMs)
+			}
+			rep.Packets = append(rep.Packets, p)
+		}
+	}
+
+	sort.Slice(rep.Packets, func(i, j int) bool { return rep.Packets[i].SentAt.Before(rep.Packets[j].SentAt) })
+	rep.Commit, rep.Recv, rep.Ack = latencies(commits), latencies(recvs), latencies(acks)
+	return rep
+}
+
+func sinceMs(start, end time.Time) float64 {
+	return float64(end.Sub(start)) / float64(time.Millisecond)
+}
+
+func sumFailures(failures map[string]int) (n int) {
+	for _, f := range failures {
+		n += f
+	}
+	return n
+}
+
+// latencies returns the nearest-rank percentiles of the latencies
+func latencies(ms []float64) GunLatency {
+	if len(ms) == 0 {
+		return GunLatency{}
+	}
+	sort.Float64s(ms)
+	rank := func(p float64) float64 {
+		i := int(p*float64(len(ms))+0.5) - 1
+		if i < 0 {
+			i = 0
+		} else if i >= len(ms) {
+			i = len(ms) - 1
+		}
+		return ms[i]
+	}
+	return GunLatency{Count: len(ms), P50: rank(0.5), P90: rank(0.9), P99: rank(0.99)}
+}
+
+// Log prints a summary of the report
+func (rep *GunReport) Log(c *Chain) {
+	c.Log(fmt.Sprintf("★ Gun report: %d transfers sent, %d committed, %d received, %d acknowledged",
+		rep.Sent, rep.Committed, rep.Received, rep.Acked))
+	for _, l := range []struct {
+		stage string
+		lat   GunLatency
+	}{{"commit", rep.Commit}, {"recv", rep.Recv}, {"ack", rep.Ack}} {
+		if l.lat.Count > 0 {
+			c.Log(fmt.Sprintf("- %s latency of %d packets: p50(%.0fms) p90(%.0fms) p99(%.0fms)",
+				l.stage, l.lat.Count, l.lat.P50, l.lat.P90, l.lat.P99))
+		}
+	}
+
+	reasons := make([]string, 0, len(rep.Failures))
+	for reason := range rep.Failures {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		c.Log(fmt.Sprintf("- %d transfers failed: %s", rep.Failures[reason], reason))
+	}
+}
+
+// Write writes the report to the file, as CSV with one row per packet if it has a .csv
+// extension and as JSON otherwise
+func (rep *GunReport) Write(file string) error {
+	if !strings.EqualFold(filepath.Ext(file), ".csv") {
+		out, err := json.MarshalIndent(rep, "", "  ")
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(file, append(out, '\n'), 0644)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err = w.Write([]string{"phase", "src-chain-id", "dst-chain-id", "src-channel", "sequence", "send-tx",
+		"send-height", "recv-height", "ack-height", "sent-at", "commit-ms", "recv-ms", "ack-ms"}); err != nil {
+		return err
+	}
+	for _, p := range rep.Packets {
+		if err = w.Write([]string{p.Phase, p.SrcChainID, p.DstChainID, p.SrcChannel,
+			strconv.FormatUint(p.Sequence, 10), p.SendTx, strconv.FormatInt(p.SendHeight, 10),
+			strconv.FormatInt(p.RecvHeight, 10), strconv.FormatInt(p.AckHeight, 10), p.SentAt.Format(time.RFC3339Nano),
+			strconv.FormatFloat(p.CommitMs, 'f', 1, 64), strconv.FormatFloat(p.RecvMs, 'f', 1, 64),
+			strconv.FormatFloat(p.AckMs, 'f', 1, 64)}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,133 @@
+package relayer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+func TestLatencies(t *testing.T) {
+	series := func(n int) []float64 {
+		ms := make([]float64, n)
+		for i := range ms {
+			ms[i] = float64(n - i) // reversed, so they have to be sorted
+		}
+		return ms
+	}
+	tests := []struct {
+		name string
+		ms   []float64
+		want GunLatency
+	}{
+		{"none", nil, GunLatency{}},
+		{"single", []float64{42}, GunLatency{Count: 1, P50: 42, P90: 42, P99: 42}},
+		{"pair", []float64{20, 10}, GunLatency{Count: 2, P50: 10, P90: 20, P99: 20}},
+		{"ten", series(10), GunLatency{Count: 10, P50: 5, P90: 9, P99: 10}},
+		{"hundred", series(100), GunLatency{Count: 100, P50: 50, P90: 90, P99: 99}},
+		{"thousand", series(1000), GunLatency{Count: 1000, P50: 500, P90: 900, P99: 990}},
+		{"ties", []float64{5, 5, 5, 100}, GunLatency{Count: 4, P50: 5, P90: 100, P99: 100}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, latencies(tc.ms))
+		})
+	}
+}
+
+// packetEvent returns the event of a tx committed at the height with the packet events of the type
+func packetEvent(hash string, height int64, code uint32, eventType string, packets ...gunPacketKey) ctypes.ResultEvent {
+	events := map[string][]string{"tx.hash": {hash}, "tx.height": {strconv.FormatInt(height, 10)}}
+	for _, k := range packets {
+		events[eventType+".packet_src_port"] = append(events[eventType+".packet_src_port"], k.port)
+		events[eventType+".packet_src_channel"] = append(events[eventType+".packet_src_channel"], k.channel)
+		events[eventType+".packet_sequence"] = append(events[eventType+".packet_sequence"], strconv.FormatUint(k.seq, 10))
+	}
+	result := abci.ResponseDeliverTx{Code: code}
+	if code != 0 {
+		result.Codespace = "sdk"
+	}
+	return ctypes.ResultEvent{Events: events, Data: tmtypes.EventDataTx{TxResult: tmtypes.TxResult{Height: height, Result: result}}}
+}
+
+func TestGunTracker(t *testing.T) {
+	src := &Chain{ChainID: "src", PathEnd: &PathEnd{PortID: "transfer", ChannelID: "srcxfer"}, logger: log.NewNopLogger()}
+	dst := &Chain{ChainID: "dst", PathEnd: &PathEnd{PortID: "transfer", ChannelID: "dstxfer"}, logger: log.NewNopLogger()}
+	tr := newGunTracker(src, dst)
+	tr.setPhase("soak")
+	observe := tr.observer(src, dst)
+
+	transfers := func(n int) (msgs []sdk.Msg) {
+		for i := 0; i < n; i++ {
+			msgs = append(msgs, src.PathEnd.MsgTransfer(dst.PathEnd, 100, sdk.NewCoins(sdk.NewInt64Coin("stake", 1)),
+				"cosmos1receiver", sdk.AccAddress("signer")))
+		}
+		return msgs
+	}
+	packet := func(seq uint64) gunPacketKey { return gunPacketKey{port: "transfer", channel: "srcxfer", seq: seq} }
+	other := gunPacketKey{port: "transfer", channel: "otherxfer", seq: 1}
+	t0 := time.Unix(1000, 0)
+	at := func(ms int) time.Time { return t0.Add(time.Duration(ms) * time.Millisecond) }
+
+	// a transfer of two packets committed after it was broadcast, along with another channel's packet
+	observe(sdk.TxResponse{TxHash: "aa"}, nil, transfers(2), at(0))
+	tr.record(packetEvent("AA", 10, 0, "send_packet", packet(1), packet(2), other), at(1000))
+
+	// one committed before its broadcast returned, as when it is waited on
+	tr.record(packetEvent("BB", 11, 0, "send_packet", packet(3)), at(2000))
+	observe(sdk.TxResponse{TxHash: "bb"}, nil, transfers(1), at(500))
+
+	// their recvs, along with another channel's, and an ack
+	tr.record(packetEvent("CC", 20, 0, "recv_packet", packet(1), packet(3), other), at(3000))
+	tr.record(packetEvent("DD", 12, 0, "acknowledge_packet", packet(1)), at(4000))
+
+	// transfers that failed in CheckTx, in the broadcast, in DeliverTx or weren't committed
+	observe(sdk.TxResponse{TxHash: "ee", Codespace: "sdk", Code: 13}, nil, transfers(3), at(0))
+	observe(sdk.TxResponse{}, fmt.Errorf("connection refused"), transfers(1), at(0))
+	observe(sdk.TxResponse{TxHash: "ff"}, nil, transfers(2), at(0))
+	tr.record(packetEvent("FF", 13, 5, "send_packet"), at(1000))
+	observe(sdk.TxResponse{TxHash: "gg"}, nil, transfers(1), at(0))
+
+	// other traffic
+	observe(sdk.TxResponse{TxHash: "hh"}, nil, []sdk.Msg{transferMsg(1, "1stake")}, at(0))
+	tr.record(packetEvent("II", 14, 0, "send_packet", packet(9)), at(0))
+
+	rep := tr.report(false)
+	require.Equal(t, 10, rep.Sent)
+	require.Equal(t, 3, rep.Committed)
+	require.Equal(t, 2, rep.Received)
+	require.Equal(t, 1, rep.Acked)
+	require.Equal(t, map[string]int{"sdk/13": 3, "broadcast error": 1, "sdk/5": 2}, rep.Failures)
+	require.Equal(t, GunLatency{Count: 3, P50: 1000, P90: 1500, P99: 1500}, rep.Commit)
+	require.Equal(t, GunLatency{Count: 2, P50: 2500, P90: 3000, P99: 3000}, rep.Recv)
+	require.Equal(t, GunLatency{Count: 1, P50: 4000, P90: 4000, P99: 4000}, rep.Ack)
+
+	sort.Slice(rep.Packets, func(i, j int) bool { return rep.Packets[i].Sequence < rep.Packets[j].Sequence })
+	require.Equal(t, []GunPacket{
+		{Phase: "soak", SrcChainID: "src", DstChainID: "dst", SrcChannel: "srcxfer", Sequence: 1, SendTx: "AA",
+			SendHeight: 10, RecvHeight: 20, AckHeight: 12, SentAt: at(0), CommitMs: 1000, RecvMs: 3000, AckMs: 4000},
+		{Phase: "soak", SrcChainID: "src", DstChainID: "dst", SrcChannel: "srcxfer", Sequence: 2, SendTx: "AA",
+			SendHeight: 10, SentAt: at(0), CommitMs: 1000},
+		{Phase: "soak", SrcChainID: "src", DstChainID: "dst", SrcChannel: "srcxfer", Sequence: 3, SendTx: "BB",
+			SendHeight: 11, RecvHeight: 20, SentAt: at(500), CommitMs: 1500, RecvMs: 2500},
+	}, rep.Packets)
+
+	// transfers that weren't seen committed fail once the run is over
+	require.Equal(t, 1, tr.report(true).Failures["not committed"])
+
+	// the events of other traffic are pruned once they are older than the confirm timeout
+	tr.prune(at(4000).Add(src.GetConfirmTimeout()))
+	require.Contains(t, tr.txs, "AA")
+	require.NotContains(t, tr.txs, "II")
+	require.Contains(t, tr.recvs, packet(1))
+	require.NotContains(t, tr.recvs, other)
+}
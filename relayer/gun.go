@@ -0,0 +1,640 @@
+package relayer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	retry "github.com/avast/retry-go"
+	keys "github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	xferTypes "github.com/cosmos/cosmos-sdk/x/ibc/20-transfer/types"
+	"gopkg.in/yaml.v2"
+)
+
+// Directions a gun phase can send transfers in
+const (
+	GunSrcToDst = "src-dst"
+	GunDstToSrc = "dst-src"
+	GunBoth     = "both"
+)
+
+// GunScenario is a load test run by Gun as a sequence of phases, e.g. a ramp-up, a soak and a
+// spike. Transfers are sent to the receiver on the other chain, which defaults to its key.
+type GunScenario struct {
+	SrcReceiver string `json:"src-receiver,omitempty" yaml:"src-receiver,omitempty"`
+	DstReceiver string `json:"dst-receiver,omitempty" yaml:"dst-receiver,omitempty"`
+
+	// Seed seeds the amount and denom distributions so that a run can be repeated,
+	// zero seeds them with the time
+	Seed int64 `json:"seed,omitempty" yaml:"seed,omitempty"`
+
+	// Settle is how long to wait after the last phase for its packets to be received and
+	// acknowledged, by Gun or another relayer, before reporting on them
+	Settle string `json:"settle,omitempty" yaml:"settle,omitempty"`
+
+	// Report is the file the report of the run is written to, as CSV if it has a .csv
+	// extension and as JSON otherwise
+	Report string `json:"report,omitempty" yaml:"report,omitempty"`
+
+	Phases []*GunPhase `json:"phases" yaml:"phases"`
+
+	settle time.Duration
+}
+
+// GunPhase sends rounds of transfers in a direction for a duration or a number of rounds. With
+// TPS a round is sent every second, with MsgsPerBlock one is sent every block of the sending chain.
+// OpenLoop phases don't send rounds, so they only run for a duration.
+// If Relay is set, the packets of each round are received on the other chain before the next one,
+// using the send_packet events of the round's txs.
+type GunPhase struct {
+	Name         string      `json:"name" yaml:"name"`
+	TPS          float64     `json:"tps,omitempty" yaml:"tps,omitempty"`
+	MsgsPerBlock int         `json:"msgs-per-block,omitempty" yaml:"msgs-per-block,omitempty"`
+	Duration     string      `json:"duration,omitempty" yaml:"duration,omitempty"`
+	Repeats      int         `json:"repeats,omitempty" yaml:"repeats,omitempty"`
+	Direction    string      `json:"direction,omitempty" yaml:"direction,omitempty"`
+	Relay        bool        `json:"relay,omitempty" yaml:"relay,omitempty"`
+	Amounts      []GunAmount `json:"amounts" yaml:"amounts"`
+	Denoms       []GunDenom  `json:"denoms" yaml:"denoms"`
+
+	// OpenLoop sends the transfers at TPS without waiting for them to be committed, spread over
+	// the sending chain's signers. The rate is held by a token bucket of Burst msgs, which
+	// defaults to a second's worth.
+	OpenLoop bool `json:"open-loop,omitempty" yaml:"open-loop,omitempty"`
+	Burst    int  `json:"burst,omitempty" yaml:"burst,omitempty"`
+
+	duration time.Duration
+}
+
+// GunAmount is a range the amount of a transfer is drawn from uniformly, picked with a
+// relative weight that defaults to 1
+type GunAmount struct {
+	Min    int64 `json:"min" yaml:"min"`
+	Max    int64 `json:"max" yaml:"max"`
+	Weight int   `json:"weight,omitempty" yaml:"weight,omitempty"`
+}
+
+// GunDenom is a denom picked with a relative weight that defaults to 1. Source prefixes the
+// denom as the source argument of 'rly tx transfer' does. If ChainID is set the denom is only
+// sent from that chain.
+type GunDenom struct {
+	Denom   string `json:"denom" yaml:"denom"`
+	Source  bool   `json:"source,omitempty" yaml:"source,omitempty"`
+	ChainID string `json:"chain-id,omitempty" yaml:"chain-id,omitempty"`
+	Weight  int    `json:"weight,omitempty" yaml:"weight,omitempty"`
+}
+
+// ReadGunScenario reads a scenario from a YAML file and validates it
+func ReadGunScenario(file string) (*GunScenario, error) {
+	bz, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	sc := &GunScenario{}
+	if err = yaml.UnmarshalStrict(bz, sc); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario %s: %w", file, err)
+	}
+	if err = sc.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", file, err)
+	}
+	return sc, nil
+}
+
+// Validate checks that the phases of the scenario can be run
+func (sc *GunScenario) Validate() (err error) {
+	if len(sc.Phases) == 0 {
+		return fmt.Errorf("no phases")
+	}
+	if sc.Settle != "" {
+		if sc.settle, err = time.ParseDuration(sc.Settle); err != nil {
+			return fmt.Errorf("invalid settle: %w", err)
+		}
+	}
+	for i, p := range sc.Phases {
+		if p.Name == "" {
+			p.Name = fmt.Sprintf("phase-%d", i+1)
+		}
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("phase %s: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+func (p *GunPhase) validate() (err error) {
+	switch {
+	case p.TPS < 0 || p.MsgsPerBlock < 0:
+		return fmt.Errorf("tps and msgs-per-block can't be negative")
+	case (p.TPS > 0) == (p.MsgsPerBlock > 0):
+		return fmt.Errorf("one of tps or msgs-per-block must be set")
+	case p.Repeats < 0:
+		return fmt.Errorf("repeats can't be negative")
+	case (p.Duration != "") == (p.Repeats > 0):
+		return fmt.Errorf("one of duration or repeats must be set")
+	}
+	if p.Duration != "" {
+		if p.duration, err = time.ParseDuration(p.Duration); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case p.Burst < 0:
+		return fmt.Errorf("burst can't be negative")
+	case p.OpenLoop && p.TPS == 0:
+		return fmt.Errorf("open-loop phases need a tps")
+	case p.OpenLoop && p.Repeats > 0:
+		// its transfers aren't sent in rounds, so there are none to count
+		return fmt.Errorf("open-loop phases need a duration instead of repeats")
+	case p.OpenLoop && p.Relay:
+		// its transfers aren't waited on, so there are no rounds to relay
+		return fmt.Errorf("open-loop phases can't relay")
+	case p.OpenLoop && p.Burst == 0:
+		p.Burst = int(math.Ceil(p.TPS))
+	}
+
+	switch p.Direction {
+	case "":
+		p.Direction = GunSrcToDst
+	case GunSrcToDst, GunDstToSrc, GunBoth:
+	default:
+		return fmt.Errorf("direction must be one of %s, %s or %s", GunSrcToDst, GunDstToSrc, GunBoth)
+	}
+
+	if len(p.Amounts) == 0 {
+		return fmt.Errorf("no amounts")
+	}
+	for _, a := range p.Amounts {
+		if a.Min <= 0 || a.Max < a.Min || a.Weight < 0 {
+			return fmt.Errorf("invalid amount range [%d, %d] with weight %d", a.Min, a.Max, a.Weight)
+		}
+	}
+
+	if len(p.Denoms) == 0 {
+		return fmt.Errorf("no denoms")
+	}
+	for _, d := range p.Denoms {
+		if err = sdk.ValidateDenom(d.Denom); err != nil {
+			return err
+		}
+		if d.Weight < 0 {
+			return fmt.Errorf("denom %s has a negative weight", d.Denom)
+		}
+	}
+	return nil
+}
+
+// gunLeg sends a phase's transfers in one direction
+type gunLeg struct {
+	from, to *Chain
+	receiver string
+	denoms   []GunDenom
+	height   int64
+
+	dstHeight  uint64
+	dstUpdated time.Time
+
+	// the transfers of the leg committed by its rounds
+	sent int64
+}
+
+// gunBatch is a round of transfers sent on a leg, along with the height of the receiving chain
+// their timeouts are relative to
+type gunBatch struct {
+	msgs      []sdk.Msg
+	dstHeight uint64
+}
+
+// Gun runs the phases of the scenario in order, sending transfers between src and dst
+func (src *Chain) Gun(dst *Chain, sc *GunScenario) error {
+	seed := sc.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rnd := rand.New(rand.NewSource(seed))
+
+	receivers := map[string]string{}
+	for _, r := range []struct {
+		c    *Chain
+		addr string
+	}{{src, sc.SrcReceiver}, {dst, sc.DstReceiver}} {
+		done := r.c.UseSDKContext()
+		if r.addr == "" {
+			r.addr = r.c.MustGetAddress().String()
+		} else if _, err := sdk.AccAddressFromBech32(r.addr); err != nil {
+			done()
+			return fmt.Errorf("invalid receiver on %s: %w", r.c.ChainID, err)
+		}
+		done()
+		receivers[r.c.ChainID] = r.addr
+	}
+
+	// the transfers are only tracked once they are broadcast
+	if src.broadcastDisabled() {
+		// a single round is sent, as nothing can be relayed
+		return src.gunPhase(dst, sc.Phases[0], rnd, receivers)
+	}
+
+	tracker, err := startGunTracker(src, dst)
+	if err != nil {
+		return err
+	}
+	defer tracker.stop()
+
+	for _, p := range sc.Phases {
+		tracker.setPhase(p.Name)
+		if err = src.gunPhase(dst, p, rnd, receivers); err != nil {
+			return err
+		}
+	}
+
+	rep := tracker.finish(sc.settle)
+	rep.Log(src)
+	if sc.Report != "" {
+		if err = rep.Write(sc.Report); err != nil {
+			return err
+		}
+		src.Log(fmt.Sprintf("- gun report written to %s", sc.Report))
+	}
+	return nil
+}
+
+// gunPhase sends rounds of the phase's transfers until its duration or repeats are up
+func (src *Chain) gunPhase(dst *Chain, p *GunPhase, rnd *rand.Rand, receivers map[string]string) error {
+	var legs []*gunLeg
+	if p.Direction != GunDstToSrc {
+		legs = append(legs, &gunLeg{from: src, to: dst, receiver: receivers[dst.ChainID]})
+	}
+	if p.Direction != GunSrcToDst {
+		legs = append(legs, &gunLeg{from: dst, to: src, receiver: receivers[src.ChainID]})
+	}
+	for _, l := range legs {
+		for _, d := range p.Denoms {
+			if d.ChainID == "" || d.ChainID == l.from.ChainID {
+				l.denoms = append(l.denoms, d)
+			}
+		}
+		if len(l.denoms) == 0 {
+			return fmt.Errorf("phase %s has no denoms to send from %s", p.Name, l.from.ChainID)
+		}
+	}
+
+	rate := fmt.Sprintf("%g tps", p.TPS)
+	if p.MsgsPerBlock > 0 {
+		rate = fmt.Sprintf("%d msgs per block", p.MsgsPerBlock)
+	}
+	length := p.Duration
+	if p.Repeats > 0 {
+		length = fmt.Sprintf("%d rounds", p.Repeats)
+	}
+	src.Log(fmt.Sprintf("★ Gun phase %s: %s for %s, %s, relay(%t)", p.Name, rate, length, p.Direction, p.Relay))
+
+	start := time.Now()
+	run := src.gunRounds
+	if p.OpenLoop && !src.broadcastDisabled() {
+		run = src.gunOpenLoop
+	}
+	sent, failed, err := run(legs, p, rnd)
+	if err != nil || src.broadcastDisabled() {
+		return err
+	}
+
+	elapsed := time.Since(start)
+	src.Log(fmt.Sprintf("★ Gun phase %s done in %s: %d transfers sent, %d failed",
+		p.Name, elapsed.Round(time.Millisecond), sent, failed))
+	if p.TPS > 0 && !p.OpenLoop {
+		// the open loop logs the rate of its legs as it goes
+		for _, l := range legs {
+			l.logRate(p, elapsed)
+		}
+	}
+	return nil
+}
+
+// logRate logs the rate the leg's rounds achieved over the phase against its target
+func (l *gunLeg) logRate(p *GunPhase, elapsed time.Duration) {
+	if elapsed < time.Second {
+		return
+	}
+
+	from, to := l.from, l.to
+	rate := float64(atomic.LoadInt64(&l.sent)) / elapsed.Seconds()
+	if rate < gunBehindRatio*p.TPS {
+		from.Log(fmt.Sprintf("! [%s] gun phase %s is behind its target rate to %s: %.1f of %g tps, "+
+			"as each round waits for its transfers to be committed, use open-loop to hold the rate",
+			from.ChainID, p.Name, to.ChainID, rate, p.TPS))
+		return
+	}
+	from.Log(fmt.Sprintf("- [%s] gun phase %s: %.1f of %g tps to %s", from.ChainID, p.Name, rate, p.TPS, to.ChainID))
+}
+
+// gunRounds sends the phase's transfers in rounds, waiting for each round to be committed, and
+// relayed if the phase relays, before sending the next
+func (src *Chain) gunRounds(legs []*gunLeg, p *GunPhase, rnd *rand.Rand) (sent, failed int64, err error) {
+	start := time.Now()
+	for round := 0; ; round++ {
+		if (p.Repeats > 0 && round >= p.Repeats) || (p.Repeats == 0 && time.Since(start) >= p.duration) {
+			return sent, failed, nil
+		}
+
+		count := p.MsgsPerBlock
+		if p.TPS > 0 {
+			// rounds are a second apart and carry the msgs due since the last one
+			time.Sleep(time.Until(start.Add(time.Duration(round) * time.Second)))
+			count = int(p.TPS*float64(round+1)) - int(p.TPS*float64(round))
+			if count == 0 {
+				continue
+			}
+		} else {
+			for _, l := range legs {
+				if err = l.waitForBlock(); err != nil {
+					return sent, failed, err
+				}
+			}
+		}
+
+		batches := make([]gunBatch, len(legs))
+		for i, l := range legs {
+			if batches[i], err = l.batch(count, p, rnd, l.from.MustGetAddress(), 0); err != nil {
+				return sent, failed, err
+			}
+		}
+
+		var wg sync.WaitGroup
+		hashes := make([][]string, len(legs))
+		for i, l := range legs {
+			wg.Add(1)
+			go func(i int, l *gunLeg) {
+				defer wg.Done()
+				var (
+					n   int
+					err error
+				)
+				// the keys whose txs were committed count as sent even if others failed
+				if hashes[i], n, err = l.send(batches[i]); err != nil {
+					l.from.Error(err)
+				}
+				atomic.AddInt64(&l.sent, int64(n))
+				atomic.AddInt64(&sent, int64(n))
+				atomic.AddInt64(&failed, int64(len(batches[i].msgs)-n))
+			}(i, l)
+		}
+		wg.Wait()
+
+		if src.broadcastDisabled() {
+			// the transfers weren't broadcast, so a single round is sent and nothing is relayed
+			if failed > 0 {
+				return sent, failed, fmt.Errorf("transfer failed without broadcasting")
+			}
+			return sent, failed, nil
+		}
+
+		if !p.Relay {
+			continue
+		}
+		// the legs are relayed in turn, as both update the lite clients of the chains
+		for i, l := range legs {
+			// the transfers of the signers whose txs were committed are relayed even if others failed
+			if len(hashes[i]) == 0 {
+				continue
+			}
+			if err := l.from.relayGunTransfers(l.to, hashes[i]); err != nil {
+				l.from.Error(fmt.Errorf("failed to relay gun transfers to %s: %w", l.to.ChainID, err))
+			}
+		}
+	}
+}
+
+// waitForBlock waits until the sending chain has committed a block since the leg's last round
+func (l *gunLeg) waitForBlock() error {
+	for {
+		h, err := l.from.QueryLatestHeight()
+		if err != nil {
+			return err
+		}
+		if h > l.height {
+			l.height = h
+			return nil
+		}
+		time.Sleep(confirmPollInterval)
+	}
+}
+
+// batch builds count transfers from the signer with amounts and denoms drawn from the phase's
+// distributions. Their timeouts are relative to a height of the receiving chain that is
+// refreshed once it is older than maxAge.
+func (l *gunLeg) batch(count int, p *GunPhase, rnd *rand.Rand, signer sdk.AccAddress,
+	maxAge time.Duration) (gunBatch, error) {
+	if l.dstHeight == 0 || time.Since(l.dstUpdated) >= maxAge {
+		dstHeader, err := l.to.UpdateLiteWithHeader()
+		if err != nil {
+			return gunBatch{}, err
+		}
+		l.dstHeight, l.dstUpdated = dstHeader.GetHeight(), time.Now()
+	}
+
+	b := gunBatch{msgs: make([]sdk.Msg, 0, count), dstHeight: l.dstHeight}
+	for i := 0; i < count; i++ {
+		b.msgs = append(b.msgs, l.from.PathEnd.MsgTransfer(
+			l.to.PathEnd, b.dstHeight, sdk.NewCoins(l.coin(p, rnd)), l.receiver, signer))
+	}
+	return b, nil
+}
+
+// coin draws the amount and denom of a transfer
+func (l *gunLeg) coin(p *GunPhase, rnd *rand.Rand) sdk.Coin {
+	amounts := make([]int, len(p.Amounts))
+	for i, a := range p.Amounts {
+		amounts[i] = a.Weight
+	}
+	a := p.Amounts[pickWeighted(amounts, rnd)]
+	amount := a.Min + rnd.Int63n(a.Max-a.Min+1)
+
+	denoms := make([]int, len(l.denoms))
+	for i, d := range l.denoms {
+		denoms[i] = d.Weight
+	}
+	d := l.denoms[pickWeighted(denoms, rnd)]
+	denom := fmt.Sprintf("%s/%s/%s", l.from.PathEnd.PortID, l.from.PathEnd.ChannelID, d.Denom)
+	if d.Source {
+		denom = fmt.Sprintf("%s/%s/%s", l.to.PathEnd.PortID, l.to.PathEnd.ChannelID, d.Denom)
+	}
+	return sdk.NewInt64Coin(denom, amount)
+}
+
+// pickWeighted returns the index of a weight picked in proportion to it, zero weights count as 1
+func pickWeighted(weights []int, rnd *rand.Rand) int {
+	var total int
+	for _, w := range weights {
+		if w == 0 {
+			w = 1
+		}
+		total += w
+	}
+	n := rnd.Intn(total)
+	for i, w := range weights {
+		if w == 0 {
+			w = 1
+		}
+		if n < w {
+			return i
+		}
+		n -= w
+	}
+	return len(weights) - 1
+}
+
+// send broadcasts the batch from the sending chain, spreading the transfers over the chain's
+// signers, and returns the hashes of the txs that were committed and the transfers they carried
+func (l *gunLeg) send(b gunBatch) ([]string, int, error) {
+	if signers := l.from.Signers(); len(signers) > 1 {
+		return l.from.sendTransfersFromSigners(l.to, signers, b)
+	}
+
+	txs := RelayMsgs{Src: b.msgs, Dst: []sdk.Msg{}, WaitForTxs: true}
+	if txs.SendSync(l.from, l.to); l.from.broadcastDisabled() {
+		if err := txs.dryRunError("transfer"); err != nil {
+			return nil, 0, err
+		}
+		return nil, len(b.msgs), nil
+	} else if !txs.Success() {
+		return nil, 0, fmt.Errorf("failed to send %d transfers to %s", len(b.msgs), l.to.ChainID)
+	}
+	return []string{txs.Results[0].Response.TxHash}, len(b.msgs), nil
+}
+
+// relayGunTransfers receives on dst the packets sent from src by the txs with the given hashes.
+// The data, sequence and timeouts of each packet are taken from the send_packet events of its tx,
+// so other packets sent on the channel at the same time are left to other relayers. Packets that
+// have already timed out are timed out on src instead.
+func (src *Chain) relayGunTransfers(dst *Chain, hashes []string) error {
+	var (
+		sh               *SyncHeaders
+		srcMsgs, dstMsgs []sdk.Msg
+		err              error
+	)
+
+	if err = retry.Do(func() error {
+		srcMsgs, dstMsgs = nil, nil
+
+		// the headers have to be newer than the blocks that committed the transfers
+		if sh, err = NewSyncHeaders(src, dst); err != nil {
+			return err
+		}
+
+		var rcvPackets, timeoutPackets []relayPacket
+		for _, hash := range hashes {
+			// the node may not have indexed the tx yet
+			tx, err := src.QueryTx(hash)
+			if err != nil {
+				return err
+			}
+			rcv, timeouts, err := relayPacketFromQueryResponse(src.PathEnd, dst.PathEnd, tx, sh)
+			if err != nil {
+				return fmt.Errorf("tx %s: %w", hash, err)
+			}
+			rcvPackets, timeoutPackets = append(rcvPackets, rcv...), append(timeoutPackets, timeouts...)
+		}
+
+		// the txs of concurrent signers may have been committed in any order
+		sort.Slice(rcvPackets, func(i, j int) bool { return rcvPackets[i].Seq() < rcvPackets[j].Seq() })
+		sort.Slice(timeoutPackets, func(i, j int) bool { return timeoutPackets[i].Seq() < timeoutPackets[j].Seq() })
+
+		// fetch the proofs from the sending chain for the recvs and from the receiving one for the timeouts
+		for _, rp := range rcvPackets {
+			if err = rp.FetchCommitResponse(dst, src, sh); err != nil {
+				return err
+			}
+			dstMsgs = append(dstMsgs, rp.Msg(dst, src))
+		}
+		for _, rp := range timeoutPackets {
+			if err = rp.FetchCommitResponse(src, dst, sh); err != nil {
+				return err
+			}
+			srcMsgs = append(srcMsgs, rp.Msg(src, dst))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	txs := RelayMsgs{Src: []sdk.Msg{}, Dst: []sdk.Msg{}, WaitForTxs: true}
+	if len(dstMsgs) > 0 {
+		txs.Dst = append([]sdk.Msg{dst.PathEnd.UpdateClient(sh.GetHeader(src.ChainID), dst.MustGetAddress())}, dstMsgs...)
+	}
+	if len(srcMsgs) > 0 {
+		src.Log(fmt.Sprintf("! [%s] %d gun transfers to %s timed out before being received",
+			src.ChainID, len(srcMsgs), dst.ChainID))
+		txs.Src = append([]sdk.Msg{src.PathEnd.UpdateClient(sh.GetHeader(dst.ChainID), src.MustGetAddress())}, srcMsgs...)
+	}
+	if txs.SendSync(src, dst); !txs.Success() {
+		return fmt.Errorf("failed to receive tx")
+	}
+	return nil
+}
+
+// sendTransfersFromSigners splits the batch over the given keys, broadcasts each key's share
+// from its own account concurrently and returns the hashes of the txs that were committed and
+// the transfers they carried
+func (src *Chain) sendTransfersFromSigners(dst *Chain, signers []string, b gunBatch) ([]string, int, error) {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		hashes []string
+		sent   int
+		failed int32
+		N      = uint64(len(b.msgs))
+		next   int
+	)
+
+	for i, name := range signers {
+		// spread the remainder over the first keys
+		count := N / uint64(len(signers))
+		if uint64(i) < N%uint64(len(signers)) {
+			count++
+		}
+		if count == 0 {
+			continue
+		}
+
+		info, err := src.Keybase.Key(name)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		msgs := make([]sdk.Msg, 0, count)
+		for _, msg := range b.msgs[next : next+int(count)] {
+			xfer := msg.(xferTypes.MsgTransfer)
+			msgs = append(msgs, src.PathEnd.MsgTransfer(dst.PathEnd, b.dstHeight, xfer.Amount, xfer.Receiver, info.GetAddress()))
+		}
+		next += int(count)
+
+		wg.Add(1)
+		go func(info keys.Info, msgs []sdk.Msg) {
+			defer wg.Done()
+			res, err := src.sendMsgsWithKey(msgs, info, src.BroadcastTxSync, true)
+			if err != nil || res.Code != 0 {
+				src.LogFailedTx(res, err, msgs)
+				atomic.AddInt32(&failed, 1)
+				return
+			}
+			src.LogSuccessTx(res, msgs)
+			mu.Lock()
+			hashes = append(hashes, res.TxHash)
+			sent += len(msgs)
+			mu.Unlock()
+		}(info, msgs)
+	}
+	wg.Wait()
+
+	if failed > 0 {
+		return hashes, sent, fmt.Errorf("failed to send transfers from %d of %d keys", failed, len(signers))
+	}
+	return hashes, sent, nil
+}
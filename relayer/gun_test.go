@@ -0,0 +1,135 @@
+package relayer
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPickWeighted(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights []int
+		want    []float64 // share of the picks of each weight
+	}{
+		{"single", []int{3}, []float64{1}},
+		{"even", []int{1, 1}, []float64{0.5, 0.5}},
+		{"zero counts as one", []int{0, 1}, []float64{0.5, 0.5}},
+		{"all zero", []int{0, 0, 0, 0}, []float64{0.25, 0.25, 0.25, 0.25}},
+		{"relative", []int{1, 3}, []float64{0.25, 0.75}},
+		{"zero next to heavy", []int{0, 9}, []float64{0.1, 0.9}},
+	}
+
+	const picks = 20000
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(1))
+			counts := make([]int, len(tc.weights))
+			for i := 0; i < picks; i++ {
+				n := pickWeighted(tc.weights, rnd)
+				require.True(t, n >= 0 && n < len(tc.weights), "picked %d", n)
+				counts[n]++
+			}
+			for i, share := range tc.want {
+				require.InDelta(t, share, float64(counts[i])/picks, 0.02, "weight %d", i)
+			}
+		})
+	}
+}
+
+func TestGunPhaseValidate(t *testing.T) {
+	phase := func(edit func(p *GunPhase)) *GunPhase {
+		p := &GunPhase{
+			TPS:      10,
+			Duration: "1m",
+			Amounts:  []GunAmount{{Min: 1, Max: 10}},
+			Denoms:   []GunDenom{{Denom: "stake"}},
+		}
+		if edit != nil {
+			edit(p)
+		}
+		return p
+	}
+	tests := []struct {
+		name  string
+		phase *GunPhase
+		err   bool
+	}{
+		{"valid", phase(nil), false},
+		{"msgs per block", phase(func(p *GunPhase) { p.TPS, p.MsgsPerBlock = 0, 5 }), false},
+		{"repeats", phase(func(p *GunPhase) { p.Duration, p.Repeats = "", 3 }), false},
+		{"negative tps", phase(func(p *GunPhase) { p.TPS = -1 }), true},
+		{"no rate", phase(func(p *GunPhase) { p.TPS = 0 }), true},
+		{"both rates", phase(func(p *GunPhase) { p.MsgsPerBlock = 5 }), true},
+		{"negative repeats", phase(func(p *GunPhase) { p.Repeats = -1 }), true},
+		{"no length", phase(func(p *GunPhase) { p.Duration = "" }), true},
+		{"both lengths", phase(func(p *GunPhase) { p.Repeats = 3 }), true},
+		{"bad duration", phase(func(p *GunPhase) { p.Duration = "soon" }), true},
+		{"negative burst", phase(func(p *GunPhase) { p.Burst = -1 }), true},
+		{"open loop", phase(func(p *GunPhase) { p.OpenLoop = true }), false},
+		{"open loop without tps", phase(func(p *GunPhase) { p.OpenLoop, p.TPS, p.MsgsPerBlock = true, 0, 5 }), true},
+		{"open loop relaying", phase(func(p *GunPhase) { p.OpenLoop, p.Relay = true, true }), true},
+		{"open loop repeats", phase(func(p *GunPhase) { p.OpenLoop, p.Duration, p.Repeats = true, "", 3 }), true},
+		{"both directions", phase(func(p *GunPhase) { p.Direction = GunBoth }), false},
+		{"bad direction", phase(func(p *GunPhase) { p.Direction = "up" }), true},
+		{"no amounts", phase(func(p *GunPhase) { p.Amounts = nil }), true},
+		{"zero amount", phase(func(p *GunPhase) { p.Amounts[0].Min = 0 }), true},
+		{"inverted amounts", phase(func(p *GunPhase) { p.Amounts[0].Min = 20 }), true},
+		{"negative amount weight", phase(func(p *GunPhase) { p.Amounts[0].Weight = -1 }), true},
+		{"no denoms", phase(func(p *GunPhase) { p.Denoms = nil }), true},
+		{"bad denom", phase(func(p *GunPhase) { p.Denoms[0].Denom = "!" }), true},
+		{"negative denom weight", phase(func(p *GunPhase) { p.Denoms[0].Weight = -1 }), true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.phase.validate()
+			if tc.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGunPhaseValidateDefaults(t *testing.T) {
+	p := &GunPhase{
+		TPS:      2.5,
+		Duration: "30s",
+		OpenLoop: true,
+		Amounts:  []GunAmount{{Min: 1, Max: 1}},
+		Denoms:   []GunDenom{{Denom: "stake"}},
+	}
+	require.NoError(t, p.validate())
+	require.Equal(t, GunSrcToDst, p.Direction)
+	require.Equal(t, 3, p.Burst)
+	require.Equal(t, 30*time.Second, p.duration)
+}
+
+func TestGunScenarioValidate(t *testing.T) {
+	valid := func() *GunPhase {
+		return &GunPhase{
+			TPS:     1,
+			Repeats: 1,
+			Amounts: []GunAmount{{Min: 1, Max: 1}},
+			Denoms:  []GunDenom{{Denom: "stake"}},
+		}
+	}
+
+	sc := &GunScenario{Settle: "10s", Phases: []*GunPhase{valid(), {Name: "warm", TPS: 1}}}
+	err := sc.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "phase warm")
+
+	sc = &GunScenario{Settle: "10s", Phases: []*GunPhase{valid(), valid()}}
+	require.NoError(t, sc.Validate())
+	require.Equal(t, 10*time.Second, sc.settle)
+	require.Equal(t, "phase-1", sc.Phases[0].Name)
+	require.Equal(t, "phase-2", sc.Phases[1].Name)
+
+	require.Error(t, (&GunScenario{}).Validate())
+	require.Error(t, (&GunScenario{Settle: "later", Phases: []*GunPhase{valid()}}).Validate())
+}
@@ -0,0 +1,178 @@
+package relayer
+
+import (
+	"fmt"
+	"time"
+
+	connTypes "github.com/cosmos/cosmos-sdk/x/ibc/03-connection/types"
+	chanTypes "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/types"
+	ibctypes "github.com/cosmos/cosmos-sdk/x/ibc/types"
+)
+
+const (
+	handshakeConnection = "connection"
+	handshakeChannel    = "channel"
+)
+
+// Handshake is the progress of a connection or channel handshake between two chains, as
+// last seen by the relayer. It is recorded in the relay store after every step, so that
+// a handshake that was interrupted can be told apart from one that hasn't started.
+type Handshake struct {
+	Kind       string    `json:"kind"`
+	SrcChainID string    `json:"src-chain-id"`
+	SrcID      string    `json:"src-id"`
+	SrcState   string    `json:"src-state"`
+	DstChainID string    `json:"dst-chain-id"`
+	DstID      string    `json:"dst-id"`
+	DstState   string    `json:"dst-state"`
+	Time       time.Time `json:"time"`
+}
+
+// validateConnectionEnds returns an error if a connection end that already exists on either
+// chain doesn't connect the path's clients and connections, in which case the handshake
+// can't be resumed with the path
+func validateConnectionEnds(src, dst *Chain, conns map[string]connTypes.ConnectionResponse) error {
+	for _, c := range []struct{ end, counterparty *Chain }{{src, dst}, {dst, src}} {
+		conn := conns[c.end.ChainID].Connection
+		if conn.State == ibctypes.UNINITIALIZED {
+			continue
+		}
+		if conn.ClientID != c.end.PathEnd.ClientID ||
+			conn.Counterparty.ClientID != c.counterparty.PathEnd.ClientID ||
+			conn.Counterparty.ConnectionID != c.counterparty.PathEnd.ConnectionID {
+			return fmt.Errorf("[%s]conn{%s} already exists with client{%s} and counterparty client{%s}conn{%s}, "+
+				"which don't match the path", c.end.ChainID, c.end.PathEnd.ConnectionID, conn.ClientID,
+				conn.Counterparty.ClientID, conn.Counterparty.ConnectionID)
+		}
+	}
+	return nil
+}
+
+// validateChannelEnds returns an error if a channel end that already exists on either chain
+// doesn't connect the path's connections, ports and channels with the given ordering, in
+// which case the handshake can't be resumed with the path
+func validateChannelEnds(src, dst *Chain, order ibctypes.Order, chans map[string]chanTypes.ChannelResponse) error {
+	for _, c := range []struct{ end, counterparty *Chain }{{src, dst}, {dst, src}} {
+		ch := chans[c.end.ChainID].Channel
+		if ch.State == ibctypes.UNINITIALIZED {
+			continue
+		}
+		if len(ch.ConnectionHops) == 0 || ch.ConnectionHops[0] != c.end.PathEnd.ConnectionID ||
+			ch.Counterparty.PortID != c.counterparty.PathEnd.PortID ||
+			ch.Counterparty.ChannelID != c.counterparty.PathEnd.ChannelID {
+			return fmt.Errorf("[%s]chan{%s}port{%s} already exists with connection hops %v and counterparty "+
+				"chan{%s}port{%s}, which don't match the path", c.end.ChainID, c.end.PathEnd.ChannelID,
+				c.end.PathEnd.PortID, ch.ConnectionHops, ch.Counterparty.ChannelID, ch.Counterparty.PortID)
+		}
+		if ch.Ordering != order {
+			return fmt.Errorf("[%s]chan{%s}port{%s} already exists as %s, not %s", c.end.ChainID,
+				c.end.PathEnd.ChannelID, c.end.PathEnd.PortID, ch.Ordering, order)
+		}
+	}
+	return nil
+}
+
+// resumeHandshake logs the state a handshake is resumed from, if it has already started,
+// and records it in the relay store
+func resumeHandshake(src, dst *Chain, h Handshake) {
+	if h.SrcState != ibctypes.UNINITIALIZED.String() || h.DstState != ibctypes.UNINITIALIZED.String() {
+		last := ""
+		if src.store != nil {
+			if prev, ok := src.store.Handshake(h.Kind, h.SrcChainID, h.SrcID, h.DstChainID, h.DstID); ok {
+				last = fmt.Sprintf(" last stepped at %s", prev.Time.Format(time.RFC3339))
+			}
+		}
+		src.Log(fmt.Sprintf("- resuming %s handshake%s: [%s]%s{%s} : [%s]%s{%s}", h.Kind, last,
+			h.SrcChainID, h.SrcID, h.SrcState, h.DstChainID, h.DstID, h.DstState))
+	}
+	src.recordHandshake(h)
+}
+
+// recordHandshake records the progress of the handshake in the relay store, if the chain has one
+func (src *Chain) recordHandshake(h Handshake) {
+	if src.store == nil {
+		return
+	}
+	h.Time = time.Now()
+	if err := src.store.SetHandshake(h); err != nil {
+		src.Error(fmt.Errorf("failed to record %s handshake: %w", h.Kind, err))
+	}
+}
+
+// recordConnectionProgress queries the connection ends and records them in the relay store
+func recordConnectionProgress(src, dst *Chain) {
+	if src.store == nil {
+		return
+	}
+	conns, err := QueryConnectionPair(src, dst, 0, 0)
+	if err != nil {
+		src.Error(fmt.Errorf("failed to query connection handshake: %w", err))
+		return
+	}
+	src.recordHandshake(connectionHandshake(src, dst, conns))
+}
+
+// recordChannelProgress queries the channel ends and records them in the relay store
+func recordChannelProgress(src, dst *Chain) {
+	if src.store == nil {
+		return
+	}
+	chans, err := QueryChannelPair(src, dst, 0, 0)
+	if err != nil {
+		src.Error(fmt.Errorf("failed to query channel handshake: %w", err))
+		return
+	}
+	src.recordHandshake(channelHandshake(src, dst, chans))
+}
+
+// awaitPendingTxs waits up to the confirm timeout for txs recorded as pending in the relay store
+// of either chain to be committed or dropped
+func awaitPendingTxs(src, dst *Chain) {
+	deadline := time.Now().Add(src.GetConfirmTimeout())
+	for {
+		reconcileRelayStores(src, dst)
+
+		var pending int
+		for _, c := range []*Chain{src, dst} {
+			if c.store == nil {
+				continue
+			}
+			txs, err := c.store.PendingTxs(c.ChainID)
+			if err != nil {
+				c.Error(fmt.Errorf("failed to read pending txs: %w", err))
+				continue
+			}
+			pending += len(txs)
+		}
+
+		if pending == 0 || time.Now().After(deadline) {
+			return
+		}
+		src.Log(fmt.Sprintf("- waiting for %d pending txs from an earlier run", pending))
+		time.Sleep(confirmPollInterval)
+	}
+}
+
+func connectionHandshake(src, dst *Chain, conns map[string]connTypes.ConnectionResponse) Handshake {
+	return Handshake{
+		Kind:       handshakeConnection,
+		SrcChainID: src.ChainID,
+		SrcID:      src.PathEnd.ConnectionID,
+		SrcState:   conns[src.ChainID].Connection.State.String(),
+		DstChainID: dst.ChainID,
+		DstID:      dst.PathEnd.ConnectionID,
+		DstState:   conns[dst.ChainID].Connection.State.String(),
+	}
+}
+
+func channelHandshake(src, dst *Chain, chans map[string]chanTypes.ChannelResponse) Handshake {
+	return Handshake{
+		Kind:       handshakeChannel,
+		SrcChainID: src.ChainID,
+		SrcID:      fmt.Sprintf("%s/%s", src.PathEnd.PortID, src.PathEnd.ChannelID),
+		SrcState:   chans[src.ChainID].Channel.State.String(),
+		DstChainID: dst.ChainID,
+		DstID:      fmt.Sprintf("%s/%s", dst.PathEnd.PortID, dst.PathEnd.ChannelID),
+		DstState:   chans[dst.ChainID].Channel.State.String(),
+	}
+}
@@ -56,6 +56,16 @@ func (c *Chain) SetPath(p *PathEnd) error {
 	return nil
 }
 
+// withPath returns a copy of the chain with the path set. The copy shares
+// the RPC client, keybase and codecs of the original chain.
+func (c *Chain) withPath(p *PathEnd) (*Chain, error) {
+	out := *c
+	if err := out.SetPath(p); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 // AddPath takes the elements of a path and validates then, setting that path to the chain
 func (c *Chain) AddPath(clientID, connectionID, channelID, port, order string) error {
 	return c.SetPath(&PathEnd{ChainID: c.ChainID, ClientID: clientID, ConnectionID: connectionID, ChannelID: channelID, PortID: port, Order: order})
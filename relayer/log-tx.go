@@ -40,6 +40,28 @@ func (c *Chain) logPacketsRelayed(dst *Chain, num int) {
 	dst.Log(fmt.Sprintf("★ Relayed %d packets: [%s]port{%s}->[%s]port{%s}", num, dst.ChainID, dst.PathEnd.PortID, c.ChainID, c.PathEnd.PortID))
 }
 
+func (c *Chain) logPacketsTimedOut(dst *Chain, num int) {
+	c.Log(fmt.Sprintf("★ Timed out %d packets: [%s]port{%s}->[%s]port{%s}", num, c.ChainID, c.PathEnd.PortID, dst.ChainID, dst.PathEnd.PortID))
+}
+
+func (c *Chain) logAcksRelayed(dst *Chain, num int) {
+	c.Log(fmt.Sprintf("★ Relayed %d acknowledgements: [%s]port{%s}->[%s]port{%s}", num, dst.ChainID, dst.PathEnd.PortID, c.ChainID, c.PathEnd.PortID))
+}
+
+func (c *Chain) logPacketTimeout(dst *Chain, rp relayPacket) {
+	c.Log(fmt.Sprintf("- [%s] -> packet seq(%d) to [%s] expired timeout-height(%d), timing out",
+		c.ChainID, rp.Seq(), dst.ChainID, rp.Timeout()))
+}
+
+func (c *Chain) logPacketFiltered(rp relayPacket, err error) {
+	c.Log(fmt.Sprintf("- [%s] -> packet seq(%d) filtered, not relaying: %s", c.ChainID, rp.Seq(), err))
+}
+
+func (c *Chain) logPacketBelowValue(sp SkippedPacket, action string) {
+	c.Log(fmt.Sprintf("- [%s] -> %s for packet seq(%d) amount(%s) below value(%s), %s",
+		c.ChainID, sp.MsgType, sp.Sequence, sp.Amount, sp.Threshold, action))
+}
+
 func logChannelStates(src, dst *Chain, conn map[string]chanTypes.ChannelResponse) {
 	// TODO: replace channelID with portID?
 	src.Log(fmt.Sprintf("- [%s]@{%d}chan(%s)-{%s} : [%s]@{%d}chan(%s)-{%s}",
@@ -99,3 +121,18 @@ func getTxActions(act []string) string {
 	}
 	return strings.TrimSuffix(out, ",")
 }
+
+func (c *Chain) logDryRun(msgs []sdk.Msg, res sdk.TxResponse) {
+	c.logger.Info(fmt.Sprintf("✔ [%s] - dry run msg(%s) gas-used(%d) gas-wanted(%d)",
+		c.ChainID, getMsgAction(msgs), res.GasUsed, res.GasWanted))
+	for _, l := range res.Logs {
+		if int(l.MsgIndex) >= len(msgs) {
+			continue
+		}
+		c.Log(fmt.Sprintf("- [%s] -> msg(%d:%s) events: %s", c.ChainID, l.MsgIndex, msgs[l.MsgIndex].Type(), formatEvents(l.Events)))
+	}
+}
+
+func (c *Chain) logDryRunFailed(msgs []sdk.Msg, err error) {
+	c.logger.Info(fmt.Sprintf("✘ [%s] - dry run msg(%s) err(%s)", c.ChainID, getMsgAction(msgs), err))
+}
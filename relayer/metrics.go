@@ -0,0 +1,44 @@
+package relayer
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	metricsOnce sync.Once
+	metricsPort string
+	metricsErr  error
+)
+
+// ServeMetrics serves the metrics registered with prometheus at /metrics on the port, logging
+// through the chain. The current gas price of each chain is registered with them. The server is
+// shared by the process, so later calls reuse the one started first, whatever their port.
+func (src *Chain) ServeMetrics(port string) error {
+	metricsOnce.Do(func() {
+		metricsPort = port
+		prometheus.MustRegister(gasPriceGauge)
+		ln, err := net.Listen("tcp", ":"+port)
+		if err != nil {
+			metricsErr = fmt.Errorf("failed to serve metrics on port %s: %w", port, err)
+			return
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		go func() {
+			if err := http.Serve(ln, mux); err != nil {
+				src.Error(fmt.Errorf("metrics server on port %s stopped: %w", port, err))
+			}
+		}()
+	})
+	if metricsErr == nil && port != metricsPort {
+		src.Log(fmt.Sprintf("- metrics are already served on port %s, not %s", metricsPort, port))
+	}
+	return metricsErr
+}
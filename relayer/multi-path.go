@@ -0,0 +1,418 @@
+package relayer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultPathRestartInterval is how often a MultiPathRelayer retries paths that failed to start
+var defaultPathRestartInterval = time.Minute
+
+// MultiPathRelayer relays packets over many paths from a single process. Each chain
+// has one RPC client and one set of event subscriptions, which are shared by all of
+// the paths that use it. Events from a chain are fanned out to the strategy of each
+// of those paths.
+type MultiPathRelayer struct {
+	sync.Mutex
+
+	chains   map[string]*Chain
+	paths    map[string]*pathRelayer
+	names    []string // path names in the order they were added
+	sh       *SyncHeaders
+	doneChan chan struct{}
+}
+
+// pathRelayer holds the state of a single path in a MultiPathRelayer
+type pathRelayer struct {
+	name     string
+	src, dst *Chain
+	strategy Strategy
+	ordered  bool
+	sweep    time.Duration // how often to sweep the backlog, zero disables sweeping
+
+	active bool // events are handled for the path
+	failed bool // the path failed to start and will be retried
+}
+
+// NewMultiPathRelayer returns a MultiPathRelayer without any paths
+func NewMultiPathRelayer() *MultiPathRelayer {
+	return &MultiPathRelayer{
+		chains:   make(map[string]*Chain),
+		paths:    make(map[string]*pathRelayer),
+		doneChan: make(chan struct{}),
+	}
+}
+
+// AddPath adds a path to relay over with the given strategy. src and dst are the configured
+// chains for each end of the path. The path is relayed using copies of them, so that paths
+// which share a chain also share its RPC client. If sweepInterval is positive the backlog
+// of the path is relayed every sweepInterval. Paths must be added before calling Start.
+func (mr *MultiPathRelayer) AddPath(name string, path *Path, src, dst *Chain, strategy Strategy,
+	sweepInterval time.Duration) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	if mr.sh != nil {
+		return fmt.Errorf("can't add path %s to a running relayer", name)
+	}
+	if _, found := mr.paths[name]; found {
+		return fmt.Errorf("path with name %s already added", name)
+	}
+
+	pathSrc, err := mr.chain(src).withPath(path.Src)
+	if err != nil {
+		return err
+	}
+	pathDst, err := mr.chain(dst).withPath(path.Dst)
+	if err != nil {
+		return err
+	}
+
+	mr.paths[name] = &pathRelayer{
+		name:     name,
+		src:      pathSrc,
+		dst:      pathDst,
+		strategy: strategy,
+		ordered:  path.Ordered(),
+		sweep:    sweepInterval,
+	}
+	mr.names = append(mr.names, name)
+	return nil
+}
+
+// chain returns the chain shared by all paths with c's chain-id
+func (mr *MultiPathRelayer) chain(c *Chain) *Chain {
+	if chain, ok := mr.chains[c.ChainID]; ok {
+		return chain
+	}
+	mr.chains[c.ChainID] = c
+	return c
+}
+
+// Start subscribes to the events of every chain and starts relaying over each path.
+// Paths that fail to start are logged and retried periodically. The returned function
+// stops the relayer.
+func (mr *MultiPathRelayer) Start() (func(), error) {
+	if len(mr.paths) == 0 {
+		return nil, fmt.Errorf("no paths to relay over")
+	}
+
+	chains := make([]*Chain, 0, len(mr.chains))
+	for _, c := range mr.chains {
+		chains = append(chains, c)
+	}
+
+	// Fetch latest headers for each chain and store them in sync headers
+	sh, err := NewSyncHeaders(chains...)
+	if err != nil {
+		return nil, err
+	}
+	mr.Lock()
+	mr.sh = sh
+	mr.Unlock()
+
+	// Subscribe once to each chain and fan the events out to the paths
+	for _, c := range chains {
+		sub, err := subscribeEvents(c)
+		if err != nil {
+			// stop listening to the chains already subscribed to
+			close(mr.doneChan)
+			return nil, err
+		}
+
+		go func(sub *eventSubscription) {
+			defer func() { sub.cancel() }()
+			mr.listenLoop(sub)
+		}(sub)
+	}
+
+	for _, name := range mr.names {
+		if err = mr.StartPath(name); err != nil {
+			mr.paths[name].src.Error(err)
+		}
+	}
+
+	go mr.restartLoop()
+
+	for _, name := range mr.names {
+		if p := mr.paths[name]; p.sweep > 0 {
+			go mr.sweepLoop(p)
+		}
+	}
+
+	return func() { close(mr.doneChan) }, nil
+}
+
+// StartPath relays any backlog on the named path and starts handling its events.
+// If the backlog can't be relayed the path is marked as failed and retried later.
+func (mr *MultiPathRelayer) StartPath(name string) error {
+	mr.Lock()
+	p, ok := mr.paths[name]
+	if !ok {
+		mr.Unlock()
+		return fmt.Errorf("path with name %s does not exist", name)
+	}
+	if mr.sh == nil {
+		mr.Unlock()
+		return fmt.Errorf("can't start path %s before the relayer is started", name)
+	}
+	if p.active {
+		mr.Unlock()
+		return nil
+	}
+	// handle events while the backlog is relayed so that nothing is missed in between
+	p.active, p.failed = true, false
+	mr.Unlock()
+
+	if err := relayBacklog(p.src, p.dst, p.strategy, p.ordered, mr.sh); err != nil {
+		mr.Lock()
+		p.active, p.failed = false, true
+		mr.Unlock()
+		return fmt.Errorf("path %s failed to start: %w", name, err)
+	}
+
+	p.src.Log(fmt.Sprintf("- [%s]:{%s} <-> [%s]:{%s} relaying over path %s",
+		p.src.ChainID, p.src.PathEnd.PortID, p.dst.ChainID, p.dst.PathEnd.PortID, name))
+	return nil
+}
+
+// StopPath stops handling events for the named path. The other paths are not affected.
+func (mr *MultiPathRelayer) StopPath(name string) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	p, ok := mr.paths[name]
+	if !ok {
+		return fmt.Errorf("path with name %s does not exist", name)
+	}
+	p.active, p.failed = false, false
+	p.src.Log(fmt.Sprintf("- [%s]:{%s} <-> [%s]:{%s} stopped relaying over path %s",
+		p.src.ChainID, p.src.PathEnd.PortID, p.dst.ChainID, p.dst.PathEnd.PortID, name))
+	return nil
+}
+
+// RestartPath stops and starts the named path, relaying any backlog that built up
+func (mr *MultiPathRelayer) RestartPath(name string) error {
+	if err := mr.StopPath(name); err != nil {
+		return err
+	}
+	return mr.StartPath(name)
+}
+
+// PathStates returns whether each path is active, stopped or failed to start
+func (mr *MultiPathRelayer) PathStates() map[string]string {
+	mr.Lock()
+	defer mr.Unlock()
+
+	out := make(map[string]string, len(mr.paths))
+	for name, p := range mr.paths {
+		switch {
+		case p.active:
+			out[name] = "active"
+		case p.failed:
+			out[name] = "failed"
+		default:
+			out[name] = "stopped"
+		}
+	}
+	return out
+}
+
+// ServeControl serves the controls of ServeHTTP on the port, logging through the first path's chain
+func (mr *MultiPathRelayer) ServeControl(port string) error {
+	ln, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return fmt.Errorf("failed to serve path controls on port %s: %w", port, err)
+	}
+
+	c := mr.paths[mr.names[0]].src
+	go func() {
+		if err := http.Serve(ln, mr); err != nil {
+			c.Error(fmt.Errorf("path controls on port %s stopped: %w", port, err))
+		}
+	}()
+	return nil
+}
+
+// ServeHTTP lets the paths be controlled while the relayer runs. A GET of /paths returns
+// their states as JSON and a POST to /paths/{name}/stop, /start or /restart does that to the path.
+func (mr *MultiPathRelayer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "paths" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(mr.PathStates()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	case len(parts) == 3 && r.Method == http.MethodPost:
+		var err error
+		switch parts[2] {
+		case "stop":
+			err = mr.StopPath(parts[1])
+		case "start":
+			err = mr.StartPath(parts[1])
+		case "restart":
+			err = mr.RestartPath(parts[1])
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// listenLoop handles the events of a single chain until the relayer is stopped.
+// Subscriptions that are closed or stop delivering blocks are reconnected.
+func (mr *MultiPathRelayer) listenLoop(sub *eventSubscription) {
+	c := sub.chain
+	ticker := time.NewTicker(staleSubscriptionTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		lost := false
+		select {
+		case msg, ok := <-sub.txs:
+			if !ok {
+				lost = true
+				break
+			}
+			c.logTx(msg.Events)
+			mr.handleEvents(c.ChainID, msg.Events)
+		case msg, ok := <-sub.blocks:
+			if !ok {
+				lost = true
+				break
+			}
+			// TODO: Add debug block logging here
+			sub.lastBlock = time.Now()
+			if err := mr.sh.Update(c); err != nil {
+				c.Error(err)
+			}
+			mr.handleEvents(c.ChainID, msg.Events)
+		case <-ticker.C:
+			lost = sub.stale()
+		case <-mr.doneChan:
+			c.Log(fmt.Sprintf("- [%s] relayer shutting down", c.ChainID))
+			return
+		}
+
+		if lost {
+			if !sub.resubscribe(mr.doneChan) {
+				c.Log(fmt.Sprintf("- [%s] relayer shutting down", c.ChainID))
+				return
+			}
+			if err := mr.sh.Update(c); err != nil {
+				c.Error(err)
+			}
+			mr.catchUp(c)
+		}
+	}
+}
+
+// catchUp relays anything on the active paths that include c that was missed while c was
+// disconnected. The paths share c's client, so they already use the new one.
+func (mr *MultiPathRelayer) catchUp(c *Chain) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	for _, name := range mr.names {
+		p := mr.paths[name]
+		if c.ChainID != p.src.ChainID && c.ChainID != p.dst.ChainID {
+			continue
+		}
+
+		if p.active {
+			go func(p *pathRelayer) {
+				if err := relayBacklog(p.src, p.dst, p.strategy, p.ordered, mr.sh); err != nil {
+					p.src.Error(fmt.Errorf("path %s failed to catch up: %w", p.name, err))
+				}
+			}(p)
+		}
+	}
+}
+
+// handleEvents passes the events from the chain with chainID to the strategies
+// of the active paths that include it
+func (mr *MultiPathRelayer) handleEvents(chainID string, events map[string][]string) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	for _, name := range mr.names {
+		p := mr.paths[name]
+		if !p.active {
+			continue
+		}
+		if p.src.ChainID == chainID {
+			go p.strategy.HandleEvents(p.dst, p.src, mr.sh, events)
+		}
+		if p.dst.ChainID == chainID {
+			go p.strategy.HandleEvents(p.src, p.dst, mr.sh, events)
+		}
+	}
+}
+
+// sweepLoop relays the backlog of the path every sweep interval while it is active
+func (mr *MultiPathRelayer) sweepLoop(p *pathRelayer) {
+	ticker := time.NewTicker(p.sweep)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			mr.Lock()
+			active := p.active
+			mr.Unlock()
+			if !active {
+				continue
+			}
+
+			if err := relayBacklog(p.src, p.dst, p.strategy, p.ordered, mr.sh); err != nil {
+				p.src.Error(fmt.Errorf("path %s failed to sweep: %w", p.name, err))
+			}
+		case <-mr.doneChan:
+			return
+		}
+	}
+}
+
+// restartLoop periodically restarts the paths that failed to start
+func (mr *MultiPathRelayer) restartLoop() {
+	ticker := time.NewTicker(defaultPathRestartInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			mr.Lock()
+			var failed []string
+			for _, name := range mr.names {
+				if mr.paths[name].failed {
+					failed = append(failed, name)
+				}
+			}
+			mr.Unlock()
+
+			for _, name := range failed {
+				if err := mr.StartPath(name); err != nil {
+					mr.paths[name].src.Error(err)
+				}
+			}
+		case <-mr.doneChan:
+			return
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package relayer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestMultiPathRelayerControl(t *testing.T) {
+	chain := func(chainID string) *Chain {
+		return &Chain{ChainID: chainID, PathEnd: &PathEnd{PortID: "transfer"}, logger: log.NewNopLogger()}
+	}
+	mr := NewMultiPathRelayer()
+	mr.paths["a"] = &pathRelayer{name: "a", src: chain("ibc0"), dst: chain("ibc1"), active: true}
+	mr.paths["b"] = &pathRelayer{name: "b", src: chain("ibc1"), dst: chain("ibc2"), active: true}
+	mr.names = []string{"a", "b"}
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mr.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	tests := []struct {
+		method, path string
+		code         int
+		states       map[string]string
+	}{
+		{http.MethodPost, "/paths/a/stop", http.StatusOK, map[string]string{"a": "stopped", "b": "active"}},
+		{http.MethodPost, "/paths/a/stop", http.StatusOK, map[string]string{"a": "stopped", "b": "active"}},
+		{http.MethodPost, "/paths/c/stop", http.StatusBadRequest, map[string]string{"a": "stopped", "b": "active"}},
+		// paths can't be started before the relayer is
+		{http.MethodPost, "/paths/a/start", http.StatusBadRequest, map[string]string{"a": "stopped", "b": "active"}},
+		{http.MethodPost, "/paths/b/restart", http.StatusBadRequest, map[string]string{"a": "stopped", "b": "stopped"}},
+		{http.MethodPost, "/paths/b/pause", http.StatusNotFound, map[string]string{"a": "stopped", "b": "stopped"}},
+		{http.MethodGet, "/paths/b/stop", http.StatusNotFound, map[string]string{"a": "stopped", "b": "stopped"}},
+		{http.MethodGet, "/metrics", http.StatusNotFound, map[string]string{"a": "stopped", "b": "stopped"}},
+	}
+
+	for _, tc := range tests {
+		require.Equal(t, tc.code, do(tc.method, tc.path).Code, "%s %s", tc.method, tc.path)
+		require.Equal(t, tc.states, mr.PathStates(), "%s %s", tc.method, tc.path)
+	}
+
+	rec := do(http.MethodGet, "/paths")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"a":"stopped","b":"stopped"}`, rec.Body.String())
+}
@@ -3,14 +3,30 @@ package relayer
 import (
 	"fmt"
 	"strconv"
-	"time"
+	"sync"
 
 	retry "github.com/avast/retry-go"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	chanTypes "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/types"
+	tmclient "github.com/cosmos/cosmos-sdk/x/ibc/07-tendermint/types"
 )
 
 var _ Strategy = &NaiveStrategy{}
 
+const (
+	naiveOptMaxTxSize    = "max-tx-size"
+	naiveOptMaxMsgLength = "max-msgs"
+
+	defaultMaxTxSize    = 2 * mb
+	defaultMaxMsgLength = 5
+
+	mb = 1048576 // in bytes
+)
+
+func init() {
+	RegisterStrategy((&NaiveStrategy{}).GetType(), newNaiveStrategy, parseNaiveStrategyOptions)
+}
+
 // NewNaiveStrategy returns the proper config for the NaiveStrategy
 func NewNaiveStrategy() *StrategyCfg {
 	return &StrategyCfg{
@@ -23,6 +39,69 @@ type NaiveStrategy struct {
 	Ordered      bool
 	MaxTxSize    uint64 // maximum permitted size of the msgs in a bundled relay transaction
 	MaxMsgLength uint64 // maximum amount of messages in a bundled relay transaction
+	Filter       *PacketFilter
+
+	FeeMode     string    // relay every packet if empty, otherwise FeeModeDefer or FeeModeSkip
+	MinValue    sdk.Coins // minimum value per denom of the packets relayed when FeeMode is set
+	SkippedFile string    // file that packets skipped with FeeModeSkip are appended to
+
+	skippedMu sync.Mutex
+	skipped   map[string]SkippedPacket
+}
+
+func newNaiveStrategy() Strategy {
+	return &NaiveStrategy{
+		MaxTxSize:    defaultMaxTxSize,
+		MaxMsgLength: defaultMaxMsgLength,
+	}
+}
+
+// parseNaiveStrategyOptions sets max-tx-size (in MB), max-msgs and the fee-mode,
+// min-value and skipped-file options on a NaiveStrategy
+func parseNaiveStrategyOptions(strategy Strategy, opts map[string]string) error {
+	ns, ok := strategy.(*NaiveStrategy)
+	if !ok {
+		return fmt.Errorf("strategy type (%T) is not type NaiveStrategy", strategy)
+	}
+
+	if val, ok := opts[naiveOptMaxTxSize]; ok {
+		txSize, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", naiveOptMaxTxSize, err)
+		}
+		ns.MaxTxSize = txSize * mb
+	}
+
+	if val, ok := opts[naiveOptMaxMsgLength]; ok {
+		msgLen, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", naiveOptMaxMsgLength, err)
+		}
+		ns.MaxMsgLength = msgLen
+	}
+
+	if val, ok := opts[naiveOptFeeMode]; ok {
+		switch val {
+		case "", FeeModeDefer, FeeModeSkip:
+			ns.FeeMode = val
+		default:
+			return fmt.Errorf("%s: must be %s or %s, got %s", naiveOptFeeMode, FeeModeDefer, FeeModeSkip, val)
+		}
+	}
+
+	if val, ok := opts[naiveOptMinValue]; ok {
+		minValue, err := sdk.ParseCoins(val)
+		if err != nil {
+			return fmt.Errorf("%s: %w", naiveOptMinValue, err)
+		}
+		ns.MinValue = minValue
+	}
+
+	if val, ok := opts[naiveOptSkippedFile]; ok {
+		ns.SkippedFile = val
+	}
+
+	return nil
 }
 
 // GetType implements Strategy
@@ -30,6 +109,11 @@ func (nrs *NaiveStrategy) GetType() string {
 	return "naive"
 }
 
+// SetPacketFilter implements FilteredStrategy
+func (nrs *NaiveStrategy) SetPacketFilter(filter *PacketFilter) {
+	nrs.Filter = filter
+}
+
 // UnrelayedSequencesOrdered returns the unrelayed sequence numbers between two chains
 func (nrs *NaiveStrategy) UnrelayedSequencesOrdered(src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
 	return UnrelayedSequences(src, dst, sh)
@@ -37,24 +121,45 @@ func (nrs *NaiveStrategy) UnrelayedSequencesOrdered(src, dst *Chain, sh *SyncHea
 
 // UnrelayedSequencesUnordered returns the unrelayed sequence numbers between two chains
 func (nrs *NaiveStrategy) UnrelayedSequencesUnordered(src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
-	return UnrelayedSequences(src, dst, sh)
+	return UnrelayedSequencesUnordered(src, dst, sh)
 }
 
 // HandleEvents defines how the relayer will handle block and transaction events as they are emmited
 func (nrs *NaiveStrategy) HandleEvents(src, dst *Chain, sh *SyncHeaders, events map[string][]string) {
 	rlyPackets, err := relayPacketsFromEventListener(src.PathEnd, dst.PathEnd, events)
 	if len(rlyPackets) > 0 && err == nil {
-		nrs.sendTxFromEventPackets(src, dst, rlyPackets, sh)
+		// packets that can no longer be received on src are timed out on dst instead, whether or
+		// not they pass the filter
+		rlyPackets, timeoutPackets := splitTimedOutPackets(rlyPackets, sh.GetHeader(src.ChainID))
+		rlyPackets = nrs.Filter.filterPackets(dst, src, rlyPackets)
+		if len(rlyPackets) > 0 {
+			nrs.sendTxFromEventPackets(src, dst, rlyPackets, sh)
+		}
+		if len(timeoutPackets) > 0 {
+			nrs.sendTxFromEventPackets(dst, src, timeoutPackets, sh)
+		}
 	}
 }
 
+// splitTimedOutPackets separates the recv packets that have timed out on the receiving chain
+// with the given latest header and returns them as timeout packets
+func splitTimedOutPackets(rlyPackets []relayPacket, dstHeader *tmclient.Header) (relay, timeouts []relayPacket) {
+	for _, rp := range rlyPackets {
+		if recv, ok := rp.(*relayMsgRecvPacket); ok && recv.timedOut(dstHeader) {
+			timeouts = append(timeouts, recv.timeoutPacket())
+			continue
+		}
+		relay = append(relay, rp)
+	}
+	return
+}
+
 func relayPacketsFromEventListener(src, dst *PathEnd, events map[string][]string) (rlyPkts []relayPacket, err error) {
 	// check for send packets
 	if pdval, ok := events["send_packet.packet_data"]; ok {
 		for i, pd := range pdval {
 			// Ensure that we only relay over the channel and port specified
-			// OPTIONAL FEATURE: add additional filtering options
-			// Example Events - "transfer.amount(sdk.Coin)", "message.sender(sdk.AccAddress)"
+			// NOTE: filtering on the packet contents is done by the strategy's PacketFilter
 			srcChan, srcPort := events["send_packet.packet_src_channel"], events["send_packet.packet_src_port"]
 			dstChan, dstPort := events["send_packet.packet_dst_channel"], events["send_packet.packet_dst_port"]
 
@@ -99,7 +204,6 @@ func relayPacketsFromEventListener(src, dst *PathEnd, events map[string][]string
 	if pdval, ok := events["recv_packet.packet_data"]; ok {
 		for i, pd := range pdval {
 			// Ensure that we only relay over the channel and port specified
-			// OPTIONAL FEATURE: add additional filtering options
 			srcChan, srcPort := events["recv_packet.packet_src_channel"], events["recv_packet.packet_src_port"]
 			dstChan, dstPort := events["recv_packet.packet_dst_channel"], events["recv_packet.packet_dst_port"]
 
@@ -148,12 +252,28 @@ func relayPacketsFromEventListener(src, dst *PathEnd, events map[string][]string
 
 func (nrs *NaiveStrategy) sendTxFromEventPackets(src, dst *Chain, rlyPackets []relayPacket, sh *SyncHeaders) {
 	// fetch the proofs for the relayPackets
+	var ready []relayPacket
 	for _, rp := range rlyPackets {
 		if err := rp.FetchCommitResponse(src, dst, sh); err != nil {
 			// we don't expect many errors here because of the retry
 			// in FetchCommitResponse
 			src.Error(err)
+			continue
 		}
+		ready = append(ready, rp)
+	}
+	if len(ready) == 0 {
+		return
+	}
+	rlyPackets = ready
+
+	// build the packet msgs, leaving out any that aren't worth the fees
+	pktMsgs := make([]sdk.Msg, 0, len(rlyPackets))
+	for _, rp := range rlyPackets {
+		pktMsgs = append(pktMsgs, rp.Msg(src, dst))
+	}
+	if pktMsgs = nrs.selectByValue(src, dst, pktMsgs, sh); len(pktMsgs) == 0 {
+		return
 	}
 
 	// send the transaction, retrying if not successful
@@ -169,9 +289,7 @@ func (nrs *NaiveStrategy) sendTxFromEventPackets(src, dst *Chain, rlyPackets []r
 		}
 
 		// add the packet msgs to RelayPackets
-		for _, rp := range rlyPackets {
-			txs.Src = append(txs.Src, rp.Msg(src, dst))
-		}
+		txs.Src = append(txs.Src, pktMsgs...)
 
 		if txs.Send(src, dst); !txs.success {
 			return fmt.Errorf("failed to send packets")
@@ -184,9 +302,43 @@ func (nrs *NaiveStrategy) sendTxFromEventPackets(src, dst *Chain, rlyPackets []r
 }
 
 // RelayPacketsUnorderedChan creates transactions to relay un-relayed messages
+// Packets on an unordered channel can be received in any order, so a packet that
+// can't be relayed is logged and skipped instead of holding up the rest of the queue
+// CONTRACT: the SyncHeaders passed in here must be up to date or being kept updated
 func (nrs *NaiveStrategy) RelayPacketsUnorderedChan(src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error {
-	// TODO: Implement unordered channels
-	return nrs.RelayPacketsOrderedChan(src, dst, sp, sh)
+	// set the maximum relay transaction constraints
+	msgs := &RelayMsgs{
+		Src:          []sdk.Msg{},
+		Dst:          []sdk.Msg{},
+		MaxTxSize:    nrs.MaxTxSize,
+		MaxMsgLength: nrs.MaxMsgLength,
+		Parallel:     true,
+	}
+
+	// add messages for src -> dst
+	for _, seq := range sp.Src {
+		srcMsgs, dstMsgs, err := packetMsgFromTxQuery(src, dst, sh, seq, nrs.Filter)
+		if err != nil {
+			src.Error(fmt.Errorf("skipping unordered packet seq(%d): %w", seq, err))
+			continue
+		}
+		msgs.Src = append(msgs.Src, srcMsgs...)
+		msgs.Dst = append(msgs.Dst, dstMsgs...)
+	}
+
+	// add messages for dst -> src
+	for _, seq := range sp.Dst {
+		dstMsgs, srcMsgs, err := packetMsgFromTxQuery(dst, src, sh, seq, nrs.Filter)
+		if err != nil {
+			dst.Error(fmt.Errorf("skipping unordered packet seq(%d): %w", seq, err))
+			continue
+		}
+		msgs.Src = append(msgs.Src, srcMsgs...)
+		msgs.Dst = append(msgs.Dst, dstMsgs...)
+	}
+
+	nrs.sendRelayPackets(src, dst, msgs, sh)
+	return nil
 }
 
 // RelayPacketsOrderedChan creates transactions to clear both queues
@@ -202,35 +354,90 @@ func (nrs *NaiveStrategy) RelayPacketsOrderedChan(src, dst *Chain, sp *RelaySequ
 
 	// add messages for src -> dst
 	for _, seq := range sp.Src {
-		chain, msg, err := packetMsgFromTxQuery(src, dst, sh, seq)
+		srcMsgs, dstMsgs, err := packetMsgFromTxQuery(src, dst, sh, seq, nrs.Filter)
 		if err != nil {
 			return err
 		}
-		if chain == dst {
-			msgs.Dst = append(msgs.Dst, msg...)
-		} else {
-			msgs.Src = append(msgs.Src, msg...)
-		}
+		msgs.Src = append(msgs.Src, srcMsgs...)
+		msgs.Dst = append(msgs.Dst, dstMsgs...)
 	}
 
 	// add messages for dst -> src
 	for _, seq := range sp.Dst {
-		chain, msg, err := packetMsgFromTxQuery(dst, src, sh, seq)
+		dstMsgs, srcMsgs, err := packetMsgFromTxQuery(dst, src, sh, seq, nrs.Filter)
 		if err != nil {
 			return err
 		}
-		if chain == src {
-			msgs.Src = append(msgs.Src, msg...)
-		} else {
-			msgs.Dst = append(msgs.Dst, msg...)
+		msgs.Src = append(msgs.Src, srcMsgs...)
+		msgs.Dst = append(msgs.Dst, dstMsgs...)
+	}
+
+	nrs.sendRelayPackets(src, dst, msgs, sh)
+	return nil
+}
+
+// UnrelayedAcknowledgements returns the sequence numbers of the packets whose acknowledgements
+// have not been relayed back to the sending chain
+func (nrs *NaiveStrategy) UnrelayedAcknowledgements(src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
+	return UnrelayedAcknowledgements(src, dst, sh)
+}
+
+// RelayAcknowledgements creates transactions to relay the acknowledgements for the packets in sp.
+// Acknowledgements can be relayed in any order, so an ack that can't be relayed is logged and skipped.
+// CONTRACT: the SyncHeaders passed in here must be up to date or being kept updated
+func (nrs *NaiveStrategy) RelayAcknowledgements(src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error {
+	// set the maximum relay transaction constraints
+	msgs := &RelayMsgs{
+		Src:          []sdk.Msg{},
+		Dst:          []sdk.Msg{},
+		MaxTxSize:    nrs.MaxTxSize,
+		MaxMsgLength: nrs.MaxMsgLength,
+		Parallel:     true,
+	}
+
+	// add acks on src for packets src -> dst
+	for _, seq := range sp.Src {
+		msg, err := ackMsgFromTxQuery(src, dst, sh, seq, nrs.Filter)
+		if err != nil {
+			src.Error(fmt.Errorf("skipping ack for packet seq(%d): %w", seq, err))
+			continue
 		}
+		msgs.Src = append(msgs.Src, msg...)
+	}
+
+	// add acks on dst for packets dst -> src
+	for _, seq := range sp.Dst {
+		msg, err := ackMsgFromTxQuery(dst, src, sh, seq, nrs.Filter)
+		if err != nil {
+			dst.Error(fmt.Errorf("skipping ack for packet seq(%d): %w", seq, err))
+			continue
+		}
+		msgs.Dst = append(msgs.Dst, msg...)
 	}
 
 	if !msgs.Ready() {
-		src.Log(fmt.Sprintf("- No packets to relay between [%s]port{%s} and [%s]port{%s}", src.ChainID, src.PathEnd.PortID, dst.ChainID, dst.PathEnd.PortID))
+		src.Log(fmt.Sprintf("- No acknowledgements to relay between [%s]port{%s} and [%s]port{%s}", src.ChainID, src.PathEnd.PortID, dst.ChainID, dst.PathEnd.PortID))
 		return nil
 	}
 
+	nrs.sendRelayPackets(src, dst, msgs, sh)
+	return nil
+}
+
+// sendRelayPackets leaves out the packet msgs that aren't worth the fees and sends the rest
+func (nrs *NaiveStrategy) sendRelayPackets(src, dst *Chain, msgs *RelayMsgs, sh *SyncHeaders) {
+	msgs.Src = nrs.selectByValue(src, dst, msgs.Src, sh)
+	msgs.Dst = nrs.selectByValue(dst, src, msgs.Dst, sh)
+	sendRelayPackets(src, dst, msgs, sh)
+}
+
+// sendRelayPackets prepends the update client msgs to the packet msgs and sends them to both chains
+func sendRelayPackets(src, dst *Chain, msgs *RelayMsgs, sh *SyncHeaders) {
+	if !msgs.Ready() {
+		src.Log(fmt.Sprintf("- No packets to relay between [%s]port{%s} and [%s]port{%s}", src.ChainID, src.PathEnd.PortID, dst.ChainID, dst.PathEnd.PortID))
+		return
+	}
+
 	// Prepend non-empty msg lists with UpdateClient
 	if len(msgs.Dst) != 0 {
 		msgs.Dst = append([]sdk.Msg{dst.PathEnd.UpdateClient(sh.GetHeader(src.ChainID), dst.MustGetAddress())}, msgs.Dst...)
@@ -239,77 +446,216 @@ func (nrs *NaiveStrategy) RelayPacketsOrderedChan(src, dst *Chain, sp *RelaySequ
 		msgs.Src = append([]sdk.Msg{src.PathEnd.UpdateClient(sh.GetHeader(dst.ChainID), src.MustGetAddress())}, msgs.Src...)
 	}
 
-	// TODO: increase the amount of gas as the number of messages increases
-	// notify the user of that
-	if msgs.Send(src, dst); msgs.success {
-		if len(msgs.Dst) > 1 {
-			dst.logPacketsRelayed(src, len(msgs.Dst)-1)
+	// log the msgs in the batches that succeeded, even if others failed
+	msgs.Send(src, dst)
+	logRelayedPackets(src, dst, msgs)
+}
+
+// logRelayedPackets logs the number of packets received, timed out and
+// acknowledged on each chain. Nothing is logged if the txs weren't broadcast.
+func logRelayedPackets(src, dst *Chain, msgs *RelayMsgs) {
+	if src.broadcastDisabled() || dst.broadcastDisabled() {
+		return
+	}
+	logRelayedMsgs(dst, src, msgs.SucceededMsgs(dst.ChainID))
+	logRelayedMsgs(src, dst, msgs.SucceededMsgs(src.ChainID))
+}
+
+func logRelayedMsgs(c, counterparty *Chain, msgs []sdk.Msg) {
+	var recvs, timeouts, acks int
+	for _, msg := range msgs {
+		switch msg.(type) {
+		case chanTypes.MsgPacket:
+			recvs++
+		case chanTypes.MsgTimeout:
+			timeouts++
+		case chanTypes.MsgAcknowledgement:
+			acks++
 		}
-		if len(msgs.Src) > 1 {
-			src.logPacketsRelayed(dst, len(msgs.Src)-1)
+	}
+	if recvs > 0 {
+		c.logPacketsRelayed(counterparty, recvs)
+	}
+	if timeouts > 0 {
+		c.logPacketsTimedOut(counterparty, timeouts)
+	}
+	if acks > 0 {
+		c.logAcksRelayed(counterparty, acks)
+	}
+}
+
+// packetMsgFromTxQuery returns the sdk.Msgs to relay the packet with a given seq sent from src.
+// If the packet can still be received, srcMsgs is empty and dstMsgs contains the MsgRecvPacket
+// for dst. If the packet has timed out on dst, srcMsgs contains the MsgTimeout for src instead.
+func packetMsgFromTxQuery(src, dst *Chain, sh *SyncHeaders, seq uint64,
+	filter *PacketFilter) (srcMsgs, dstMsgs []sdk.Msg, err error) {
+	tx, err := querySendPacketTx(src, sh, seq)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rcvPackets, timeoutPackets, err := relayPacketFromQueryResponse(src.PathEnd, dst.PathEnd, tx, sh)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// a single tx may send many packets, only relay the one we are querying for
+	rcvPackets, timeoutPackets = filterPacketsBySeq(rcvPackets, seq), filterPacketsBySeq(timeoutPackets, seq)
+	if len(rcvPackets) == 0 && len(timeoutPackets) == 0 {
+		return nil, nil, fmt.Errorf("no relay msgs created from query response for seq(%d)", seq)
+	}
+	rcvPackets = filter.filterPackets(src, dst, rcvPackets)
+
+	// fetch the proof from the sending chain and return the receiving msg
+	for _, rp := range rcvPackets {
+		if err = rp.FetchCommitResponse(dst, src, sh); err != nil {
+			return nil, nil, err
 		}
+		dstMsgs = append(dstMsgs, rp.Msg(dst, src))
 	}
 
-	return nil
+	// fetch the timeout proof from the receiving chain and return the timeout msg
+	for _, rp := range timeoutPackets {
+		if err = rp.FetchCommitResponse(src, dst, sh); err != nil {
+			return nil, nil, err
+		}
+		src.logPacketTimeout(dst, rp)
+		srcMsgs = append(srcMsgs, rp.Msg(src, dst))
+	}
+
+	return srcMsgs, dstMsgs, nil
 }
 
-// packetMsgFromTxQuery returns a sdk.Msg to relay a packet with a given seq on src
-func packetMsgFromTxQuery(src, dst *Chain, sh *SyncHeaders, seq uint64) (*Chain, []sdk.Msg, error) {
+// querySendPacketTx returns the transaction that sent the packet with a given seq from src
+func querySendPacketTx(src *Chain, sh *SyncHeaders, seq uint64) (sdk.TxResponse, error) {
 	eveSend, err := ParseEvents(fmt.Sprintf(defaultPacketSendQuery, src.PathEnd.ChannelID, seq))
 	if err != nil {
-		return nil, nil, err
+		return sdk.TxResponse{}, err
 	}
 
-	msgs := make([]sdk.Msg, 0)
-
 	tx, err := src.QueryTxs(sh.GetHeight(src.ChainID), 1, 1000, eveSend)
 	switch {
 	case err != nil:
-		return nil, nil, err
+		return sdk.TxResponse{}, err
 	case tx.Count == 0:
-		return nil, nil, fmt.Errorf("no transactions returned with query")
+		return sdk.TxResponse{}, fmt.Errorf("no transactions returned with query")
 	case tx.Count > 1:
-		return nil, nil, fmt.Errorf("more than one transaction returned with query")
+		return sdk.TxResponse{}, fmt.Errorf("more than one transaction returned with query")
+	}
+	return tx.Txs[0], nil
+}
+
+// ackMsgFromTxQuery returns the MsgAcknowledgement for src for the packet with a given
+// seq sent from src and received on dst
+func ackMsgFromTxQuery(src, dst *Chain, sh *SyncHeaders, seq uint64, filter *PacketFilter) ([]sdk.Msg, error) {
+	eveRecv, err := ParseEvents(fmt.Sprintf(defaultPacketAckQuery, dst.PathEnd.ChannelID, seq))
+	if err != nil {
+		return nil, err
 	}
 
-	rcvPackets, timeoutPackets, err := relayPacketFromQueryResponse(src.PathEnd, dst.PathEnd, tx.Txs[0], sh)
+	tx, err := dst.QueryTxs(sh.GetHeight(dst.ChainID), 1, 1000, eveRecv)
 	switch {
 	case err != nil:
-	case len(rcvPackets) == 0 && len(timeoutPackets) == 0:
-		return nil, nil, fmt.Errorf("no relay msgs created from query response")
-		//case len(rcvPackets)+len(timeoutPackets) > 1:
-		//	return nil, nil, fmt.Errorf("more than one relay msg found in tx query")
+		return nil, err
+	case tx.Count == 0:
+		return nil, fmt.Errorf("no transactions returned with query")
+	case tx.Count > 1:
+		return nil, fmt.Errorf("more than one transaction returned with query")
 	}
 
-	// sanity check the sequence number against the one we are querying for
-	// TODO: move this into relayPacketFromQueryResponse?
-	//if seq != rlyPackets[0].Seq() {
-	//	return nil, fmt.Errorf("Different sequence number from query (%d vs %d)", seq, rlyPackets[0].Seq())
-	//}
+	ackPackets := filterPacketsBySeq(relayAcksFromQueryResponse(src.PathEnd, dst.PathEnd, tx.Txs[0]), seq)
+	if len(ackPackets) == 0 {
+		return nil, fmt.Errorf("no ack msgs created from query response for seq(%d)", seq)
+	}
+	ackPackets = filter.filterPackets(src, dst, ackPackets)
 
-	// fetch the proof from the sending chain
-	for i, _ := range rcvPackets {
-		if err = rcvPackets[i].FetchCommitResponse(dst, src, sh); err != nil {
-			return nil, nil, err
+	// fetch the ack proof from the receiving chain and return the ack msg
+	msgs := make([]sdk.Msg, 0, len(ackPackets))
+	for _, rp := range ackPackets {
+		if err = rp.FetchCommitResponse(src, dst, sh); err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, rp.Msg(src, dst))
+	}
+	return msgs, nil
+}
+
+// relayAcksFromQueryResponse looks through the recv_packet events in a sdk.TxResponse from dst
+// and returns the ack packets for the packets sent from src
+func relayAcksFromQueryResponse(src, dst *PathEnd, res sdk.TxResponse) (ackPackets []relayPacket) {
+	for _, l := range res.Logs {
+		for _, e := range l.Events {
+			if e.Type != "recv_packet" {
+				continue
+			}
+			// NOTE: Src and Dst are not switched here
+			rp := &relayMsgPacketAck{pass: false}
+			for _, p := range e.Attributes {
+				switch p.Key {
+				case "packet_src_channel":
+					rp.pass = rp.pass || p.Value != src.ChannelID
+				case "packet_src_port":
+					rp.pass = rp.pass || p.Value != src.PortID
+				case "packet_dst_channel":
+					rp.pass = rp.pass || p.Value != dst.ChannelID
+				case "packet_dst_port":
+					rp.pass = rp.pass || p.Value != dst.PortID
+				case "packet_data":
+					rp.packetData = []byte(p.Value)
+				case "packet_ack":
+					rp.ack = []byte(p.Value)
+				case "packet_timeout_height":
+					rp.timeout, _ = strconv.ParseUint(p.Value, 10, 64)
+				case "packet_timeout_timestamp":
+					rp.timeoutStamp, _ = strconv.ParseUint(p.Value, 10, 64)
+				case "packet_sequence":
+					rp.seq, _ = strconv.ParseUint(p.Value, 10, 64)
+				}
+			}
+
+			if !rp.pass {
+				ackPackets = append(ackPackets, rp)
+			}
+		}
+	}
+	return
+}
+
+// TimedOutSequences returns the sequences in sp whose packets can no longer be received
+// and need to be timed out on the sending chain. Sequences whose send transaction
+// can't be found are left out.
+func TimedOutSequences(src, dst *Chain, sh *SyncHeaders, sp *RelaySequences) *RelaySequences {
+	return &RelaySequences{
+		Src: timedOutSequences(src, dst, sh, sp.Src),
+		Dst: timedOutSequences(dst, src, sh, sp.Dst),
+	}
+}
+
+func timedOutSequences(src, dst *Chain, sh *SyncHeaders, seqs []uint64) []uint64 {
+	out := []uint64{}
+	for _, seq := range seqs {
+		tx, err := querySendPacketTx(src, sh, seq)
+		if err != nil {
+			continue
+		}
+		_, timeoutPackets, err := relayPacketFromQueryResponse(src.PathEnd, dst.PathEnd, tx, sh)
+		if err != nil {
+			continue
+		}
+		if len(filterPacketsBySeq(timeoutPackets, seq)) > 0 {
+			out = append(out, seq)
 		}
 	}
+	return out
+}
 
-	// return the sending msg
-	for i, _ := range rcvPackets {
-		msgs = append(msgs, rcvPackets[i].Msg(dst, src))
-	}
-	return dst, msgs, nil
-	//if seq != timeoutPackets[0].Seq() {
-	//	return nil, nil, fmt.Errorf("Different sequence number from query (%d vs %d)", seq, timeoutPackets[0].Seq())
-	//}
-	//
-	//// fetch the timeout proof from the receiving chain
-	//if err = timeoutPackets[0].FetchCommitResponse(src, dst, sh); err != nil {
-	//	return nil, nil, err
-	//}
-	//
-	//// return the sending msg
-	//return src, timeoutPackets[0].Msg(src, dst), nil
+func filterPacketsBySeq(packets []relayPacket, seq uint64) (out []relayPacket) {
+	for _, rp := range packets {
+		if rp.Seq() == seq {
+			out = append(out, rp)
+		}
+	}
+	return
 }
 
 // relayPacketFromQueryResponse looks through the events in a sdk.Response
@@ -364,11 +710,11 @@ func relayPacketFromQueryResponse(src, dst *PathEnd, res sdk.TxResponse, sh *Syn
 
 				// if we have decided not to relay this packet, don't add it
 				switch {
-				case sh.GetHeight(src.ChainID) >= rp.timeout:
-					timeoutPackets = append(timeoutPackets, rp.timeoutPacket())
-				case rp.timeoutStamp != 0 && time.Now().UnixNano() >= int64(rp.timeoutStamp):
+				case rp.pass:
+					continue
+				case rp.timedOut(sh.GetHeader(dst.ChainID)):
 					timeoutPackets = append(timeoutPackets, rp.timeoutPacket())
-				case !rp.pass:
+				default:
 					rcvPackets = append(rcvPackets, rp)
 				}
 			}
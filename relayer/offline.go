@@ -0,0 +1,208 @@
+package relayer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	keys "github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/tendermint/tendermint/crypto/multisig"
+)
+
+// generateTx builds the msgs into an unsigned StdTx and writes it to GenOnlyDir, so that it can be
+// signed offline and broadcast later with BroadcastSignedTx. The tx uses up the next sequence of
+// the key, which is logged along with the account number as they are needed to sign it.
+func (src *Chain) generateTx(msgs []sdk.Msg, info keys.Info, gas uint64) (res sdk.TxResponse, err error) {
+	done := src.UseSDKContext()
+	defer done()
+
+	addr := info.GetAddress()
+	accNum, seq, err := src.sequences.next(src, addr)
+	if err != nil {
+		return res, err
+	}
+	defer func() {
+		// the sequence won't be used if the tx wasn't written
+		if err != nil {
+			src.sequences.resync(addr)
+		}
+	}()
+
+	txBldr, err := src.newTxBuilder(accNum, seq)
+	if err != nil {
+		return res, err
+	}
+
+	if gas == 0 {
+		if gas, err = src.gasLimit(txBldr, msgs); err != nil {
+			return res, err
+		}
+	}
+
+	msg, err := txBldr.WithGas(gas).BuildSignMsg(msgs)
+	if err != nil {
+		return res, err
+	}
+
+	json, err := src.Amino.Codec.MarshalJSONIndent(auth.NewStdTx(msg.Msgs, msg.Fee, nil, msg.Memo), "", "  ")
+	if err != nil {
+		return res, err
+	}
+
+	if err = os.MkdirAll(src.GenOnlyDir, 0755); err != nil {
+		return res, err
+	}
+
+	file := path.Join(src.GenOnlyDir, fmt.Sprintf("%s-%s-%d.json", src.ChainID, info.GetName(), seq))
+	if err = ioutil.WriteFile(file, append(json, '\n'), 0644); err != nil {
+		return res, err
+	}
+
+	src.Log(fmt.Sprintf("- [%s] -> msg(%s) unsigned tx written to %s: account-number(%d) sequence(%d)",
+		src.ChainID, getMsgAction(msgs), file, accNum, seq))
+	return res, nil
+}
+
+// ReadStdTx reads a StdTx from a JSON file
+func (src *Chain) ReadStdTx(file string) (tx auth.StdTx, err error) {
+	bz, err := ioutil.ReadFile(file)
+	if err != nil {
+		return tx, err
+	}
+	err = src.Amino.UnmarshalJSON(bz, &tx)
+	return tx, err
+}
+
+// ReadStdSignature reads a StdSignature from a JSON file
+func (src *Chain) ReadStdSignature(file string) (sig auth.StdSignature, err error) {
+	bz, err := ioutil.ReadFile(file)
+	if err != nil {
+		return sig, err
+	}
+	err = src.Amino.UnmarshalJSON(bz, &sig)
+	return sig, err
+}
+
+// SignerAccount queries the account number and sequence to sign a tx from the address with. When
+// the chain can't be reached they have to be passed to SignTx from the output of generateTx.
+func (src *Chain) SignerAccount(addr sdk.AccAddress) (accNum, seq uint64, err error) {
+	done := src.UseSDKContext()
+	defer done()
+
+	return queryAccount(src, addr)
+}
+
+// signBytes returns the bytes the signers of the tx sign for the given account number and sequence
+func (src *Chain) signBytes(tx auth.StdTx, accNum, seq uint64) []byte {
+	done := src.UseSDKContext()
+	defer done()
+
+	return auth.StdSignBytes(src.ChainID, accNum, seq, tx.Fee, tx.GetMsgs(), tx.GetMemo())
+}
+
+// SignTx signs the tx with the named key and returns it with the signature appended to any it
+// already has. The key must be one of the tx's signers.
+func (src *Chain) SignTx(tx auth.StdTx, keyName string, accNum, seq uint64) (auth.StdTx, error) {
+	sig, err := src.signStdTx(tx, keyName, tx.GetSigners(), accNum, seq)
+	if err != nil {
+		return tx, err
+	}
+	return auth.NewStdTx(tx.GetMsgs(), tx.Fee, append(tx.Signatures, sig), tx.GetMemo()), nil
+}
+
+// SignTxForMultisig signs the tx with the named key on behalf of the multisig account, which must
+// be one of the tx's signers. The signature is combined with those of the other members by
+// MultisignTx.
+func (src *Chain) SignTxForMultisig(tx auth.StdTx, keyName string, multisigAddr sdk.AccAddress,
+	accNum, seq uint64) (auth.StdSignature, error) {
+	if !isSigner(multisigAddr, tx.GetSigners()) {
+		return auth.StdSignature{}, fmt.Errorf("%s is not a signer of the tx", multisigAddr)
+	}
+	return src.signStdTx(tx, keyName, nil, accNum, seq)
+}
+
+func (src *Chain) signStdTx(tx auth.StdTx, keyName string, signers []sdk.AccAddress,
+	accNum, seq uint64) (auth.StdSignature, error) {
+	info, err := src.Keybase.Key(keyName)
+	if err != nil {
+		return auth.StdSignature{}, err
+	}
+	if signers != nil && !isSigner(info.GetAddress(), signers) {
+		return auth.StdSignature{}, fmt.Errorf("key %s is not a signer of the tx", keyName)
+	}
+
+	sig, pubKey, err := src.Keybase.Sign(keyName, src.signBytes(tx, accNum, seq))
+	if err != nil {
+		return auth.StdSignature{}, err
+	}
+	return auth.StdSignature{PubKey: pubKey.Bytes(), Signature: sig}, nil
+}
+
+// MultisignTx combines the signatures of the members of the named multisig key into a
+// signature of the multisig account, and returns the tx signed with it
+func (src *Chain) MultisignTx(tx auth.StdTx, multisigKey string, sigs []auth.StdSignature,
+	accNum, seq uint64) (auth.StdTx, error) {
+	info, err := src.Keybase.Key(multisigKey)
+	if err != nil {
+		return tx, err
+	}
+	multisigPub, ok := info.GetPubKey().(multisig.PubKeyMultisigThreshold)
+	if !ok {
+		return tx, fmt.Errorf("key %s is not a multisig key", multisigKey)
+	}
+	if !isSigner(info.GetAddress(), tx.GetSigners()) {
+		return tx, fmt.Errorf("key %s is not a signer of the tx", multisigKey)
+	}
+
+	signBytes := src.signBytes(tx, accNum, seq)
+	multisigSig := multisig.NewMultisig(len(multisigPub.PubKeys))
+	for i, sig := range sigs {
+		pubKey := sig.GetPubKey()
+		if pubKey == nil || !pubKey.VerifyBytes(signBytes, sig.Signature) {
+			return tx, fmt.Errorf("signature %d doesn't verify", i)
+		}
+		if err = multisigSig.AddSignatureFromPubKey(sig.Signature, pubKey, multisigPub.PubKeys); err != nil {
+			return tx, err
+		}
+	}
+
+	sigBytes, err := src.Amino.Codec.MarshalBinaryBare(multisigSig)
+	if err != nil {
+		return tx, err
+	}
+
+	sig := auth.StdSignature{PubKey: multisigPub.Bytes(), Signature: sigBytes}
+	return auth.NewStdTx(tx.GetMsgs(), tx.Fee, append(tx.Signatures, sig), tx.GetMemo()), nil
+}
+
+// BroadcastSignedTx broadcasts the signed tx with the chain's broadcast mode and waits for it to be
+// included in a block
+func (src *Chain) BroadcastSignedTx(tx auth.StdTx) (sdk.TxResponse, error) {
+	txBytes, err := auth.DefaultTxEncoder(src.Amino.Codec)(tx)
+	if err != nil {
+		return sdk.TxResponse{}, err
+	}
+
+	res, err := src.broadcastTx(txBytes)
+	if pendingInclusion(res, err) {
+		res, err = src.WaitForTx(txHash(txBytes))
+	}
+
+	// the tx may have used a sequence handed out to one of the chain's keys
+	for _, signer := range tx.GetSigners() {
+		src.sequences.resync(signer)
+	}
+	return res, err
+}
+
+func isSigner(addr sdk.AccAddress, signers []sdk.AccAddress) bool {
+	for _, signer := range signers {
+		if signer.Equals(addr) {
+			return true
+		}
+	}
+	return false
+}
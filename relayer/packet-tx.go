@@ -2,8 +2,6 @@ package relayer
 
 import (
 	"fmt"
-	"log"
-	"net/http"
 	"time"
 
 	retry "github.com/avast/retry-go"
@@ -13,7 +11,6 @@ import (
 	commitmentypes "github.com/cosmos/cosmos-sdk/x/ibc/23-commitment/types"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 var (
@@ -25,7 +22,7 @@ var (
 	defaultMaxClockDrift   = time.Second * 10
 	defaultPacketTimeout   = 1000
 	defaultPacketSendQuery = "send_packet.packet_src_channel=%s&send_packet.packet_sequence=%d"
-	// defaultPacketAckQuery  = "recv_packet.packet_src_channel=%s&recv_packet.packet_sequence=%d"
+	defaultPacketAckQuery  = "recv_packet.packet_dst_channel=%s&recv_packet.packet_sequence=%d"
 )
 
 func defaultPacketTimeoutStamp() uint64 {
@@ -47,15 +44,12 @@ func RelayPacketsOrderedChan(src, dst *Chain, sh *SyncHeaders, sp *RelaySequence
 	if direction == "src" || direction == "both" {
 		// add messages for src -> dst
 		for _, seq := range sp.Src {
-			chain, msg, err := packetMsgFromTxQuery(src, dst, sh, seq)
+			srcMsgs, dstMsgs, err := packetMsgFromTxQuery(src, dst, sh, seq, nil)
 			if err != nil {
 				return err
 			}
-			if chain == dst {
-				msgs.Dst = append(msgs.Dst, msg...)
-			} else {
-				msgs.Src = append(msgs.Src, msg...)
-			}
+			msgs.Src = append(msgs.Src, srcMsgs...)
+			msgs.Dst = append(msgs.Dst, dstMsgs...)
 			break
 		}
 	}
@@ -63,15 +57,12 @@ func RelayPacketsOrderedChan(src, dst *Chain, sh *SyncHeaders, sp *RelaySequence
 	if direction == "dst" || direction == "both" {
 		//add messages for dst -> src
 		for _, seq := range sp.Dst {
-			chain, msg, err := packetMsgFromTxQuery(dst, src, sh, seq)
+			dstMsgs, srcMsgs, err := packetMsgFromTxQuery(dst, src, sh, seq, nil)
 			if err != nil {
 				return err
 			}
-			if chain == src {
-				msgs.Src = append(msgs.Src, msg...)
-			} else {
-				msgs.Dst = append(msgs.Dst, msg...)
-			}
+			msgs.Src = append(msgs.Src, srcMsgs...)
+			msgs.Dst = append(msgs.Dst, dstMsgs...)
 			break
 		}
 	}
@@ -89,17 +80,49 @@ func RelayPacketsOrderedChan(src, dst *Chain, sh *SyncHeaders, sp *RelaySequence
 		msgs.Src = append([]sdk.Msg{src.PathEnd.UpdateClient(sh.GetHeader(dst.ChainID), src.MustGetAddress())}, msgs.Src...)
 	}
 
-	// TODO: increase the amount of gas as the number of messages increases
-	// notify the user of that
-	if msgs.Send(src, dst); msgs.success {
-		if len(msgs.Dst) > 1 {
-			dst.logPacketsRelayed(src, len(msgs.Dst)-1)
+	// log the msgs in the batches that succeeded, even if others failed
+	msgs.Send(src, dst)
+	logRelayedPackets(src, dst, msgs)
+
+	return nil
+}
+
+// RelayAcknowledgements creates transactions to relay the acknowledgements of the packets in ap.
+// A direction of "src" relays the acks for packets sent from src, "dst" those for packets sent
+// from dst, and "both" relays all of them.
+// CONTRACT: the SyncHeaders passed in here must be up to date or being kept updated
+func RelayAcknowledgements(src, dst *Chain, sh *SyncHeaders, ap *RelaySequences, direction string) error {
+	msgs := &RelayMsgs{
+		Src: []sdk.Msg{},
+		Dst: []sdk.Msg{},
+	}
+
+	if direction == "src" || direction == "both" {
+		for _, seq := range ap.Src {
+			msg, err := ackMsgFromTxQuery(src, dst, sh, seq, nil)
+			if err != nil {
+				return err
+			}
+			msgs.Src = append(msgs.Src, msg...)
 		}
-		if len(msgs.Src) > 1 {
-			src.logPacketsRelayed(dst, len(msgs.Src)-1)
+	}
+
+	if direction == "dst" || direction == "both" {
+		for _, seq := range ap.Dst {
+			msg, err := ackMsgFromTxQuery(dst, src, sh, seq, nil)
+			if err != nil {
+				return err
+			}
+			msgs.Dst = append(msgs.Dst, msg...)
 		}
 	}
 
+	if !msgs.Ready() {
+		src.Log(fmt.Sprintf("- No acknowledgements to relay between [%s]port{%s} and [%s]port{%s}", src.ChainID, src.PathEnd.PortID, dst.ChainID, dst.PathEnd.PortID))
+		return nil
+	}
+
+	sendRelayPackets(src, dst, msgs, sh)
 	return nil
 }
 
@@ -131,7 +154,10 @@ func (src *Chain) SendTransferBothSides(dst *Chain, amount sdk.Coin, dstAddr sdk
 		Dst: []sdk.Msg{},
 	}
 
-	if txs.Send(src, dst); !txs.Success() {
+	if txs.Send(src, dst); src.broadcastDisabled() {
+		// the packet wasn't broadcast, so there is nothing to relay
+		return txs.dryRunError("transfer")
+	} else if !txs.Success() {
 		return fmt.Errorf("failed to send first transaction")
 	}
 
@@ -272,169 +298,6 @@ func (src *Chain) SendPacket(dst *Chain, packetData []byte) error {
 	return nil
 }
 
-func (src *Chain) Gun(dst *Chain, amount sdk.Coin, dstAddr sdk.AccAddress, source bool, msgsCount, repeats int, relay bool) error {
-
-	if source {
-		amount.Denom = fmt.Sprintf("%s/%s/%s", dst.PathEnd.PortID, dst.PathEnd.ChannelID, amount.Denom)
-	} else {
-		amount.Denom = fmt.Sprintf("%s/%s/%s", src.PathEnd.PortID, src.PathEnd.ChannelID, amount.Denom)
-	}
-
-	forever := repeats == 0
-
-	srcAddress := src.MustGetAddress()
-
-	for repeats > 0 || forever {
-		repeats--
-
-		var (
-			done          func()
-			timeoutHeight uint64
-			dstAddrString string
-			txs           RelayMsgs
-		)
-		dstHeader, err := dst.UpdateLiteWithHeader()
-		if err != nil {
-			return err
-		}
-
-		timeoutHeight = dstHeader.GetHeight() + uint64(defaultPacketTimeout)
-
-		// Properly render the address string
-		done = dst.UseSDKContext()
-		dstAddrString = dstAddr.String()
-		done()
-
-		N := uint64(msgsCount)
-
-		msgs := make([]sdk.Msg, 0, N)
-
-		fmt.Println("Generating msgs....")
-
-		coins := sdk.NewCoins(amount)
-
-		for i := uint64(0); i < N; i++ {
-			msgs = append(msgs, src.PathEnd.MsgTransfer(
-				dst.PathEnd, dstHeader.GetHeight(), coins, dstAddrString, srcAddress,
-			))
-		}
-
-		// MsgTransfer will call SendPacket on src chain
-		txs = RelayMsgs{
-			Src: msgs,
-			Dst: []sdk.Msg{},
-			//MaxTxSize: 2*1048576,
-			//MaxMsgLength: 100,
-		}
-
-		fmt.Println("Sending msgs...")
-
-		if txs.SendSync(src, dst); !txs.Success() {
-			return fmt.Errorf("failed to send first transaction")
-		}
-		time.Sleep(2 * time.Second)
-		log.Println("transfer sent")
-
-		if !relay {
-			continue
-		}
-
-		var (
-			hs                 map[string]*tmclient.Header
-			seqRecv            chanTypes.RecvResponse
-			seqSend            uint64
-			srcCommitResponses []CommitmentResponse
-		)
-
-		if err = retry.Do(func() error {
-			srcCommitResponses = nil
-
-			hs, err = UpdatesWithHeaders(src, dst)
-			if err != nil {
-				return err
-			}
-
-			seqRecv, err = dst.QueryNextSeqRecv(hs[dst.ChainID].Height)
-			if err != nil {
-				return err
-			}
-
-			seqSend, err = src.QueryNextSeqSend(hs[src.ChainID].Height)
-			if err != nil {
-				return err
-			}
-
-			for i := seqSend - N; i < seqSend; i++ {
-				srcCommitRes, err := src.QueryPacketCommitment(hs[src.ChainID].Height-1, int64(i))
-				if err != nil {
-					return err
-				}
-
-				if srcCommitRes.Proof.Proof == nil {
-					return fmt.Errorf("proof nil, retrying")
-				}
-				srcCommitResponses = append(srcCommitResponses, srcCommitRes)
-			}
-
-			return nil
-		}); err != nil {
-			return err
-		}
-
-		// Properly render the source and destination address strings
-		done = src.UseSDKContext()
-		srcAddrString := src.MustGetAddress().String()
-		done()
-
-		done = dst.UseSDKContext()
-		dstAddrString = dstAddr.String()
-		done()
-
-		// reconstructing packet data here instead of retrieving from an indexed node
-		xferPacket := src.PathEnd.XferPacket(
-			sdk.NewCoins(amount),
-			srcAddrString,
-			dstAddrString,
-		)
-
-		dstMsgs := make([]sdk.Msg, 0, N+1)
-
-		dstMsgs = append(dstMsgs, dst.PathEnd.UpdateClient(hs[src.ChainID], dst.MustGetAddress()))
-
-		dstAddr := dst.MustGetAddress()
-
-		for i, srcCommitRes := range srcCommitResponses {
-			dstMsgs = append(dstMsgs,
-				dst.PathEnd.MsgRecvPacket(
-					src.PathEnd,
-					seqRecv.NextSequenceRecv+uint64(i),
-					timeoutHeight,
-					defaultPacketTimeoutStamp(),
-					xferPacket,
-					srcCommitRes.Proof,
-					srcCommitRes.ProofHeight,
-					dstAddr,
-				))
-		}
-
-		// Debugging by simply passing in the packet information that we know was sent earlier in the SendPacket
-		// part of the command. In a real relayer, this would be a separate command that retrieved the packet
-		// information from an indexing node
-		txs = RelayMsgs{
-			Dst: dstMsgs,
-			Src: []sdk.Msg{},
-			//MaxTxSize: 2*1048576,
-			//MaxMsgLength: 100,
-		}
-
-		if txs.SendSync(src, dst); !txs.Success() {
-			return fmt.Errorf("failed to receive tx")
-		}
-		log.Println("transfer received")
-	}
-	return nil
-}
-
 func (src *Chain) SlowGun(dst *Chain, timeout time.Duration, prometheusExporterPort string, back bool) error {
 
 	var (
@@ -447,15 +310,11 @@ func (src *Chain) SlowGun(dst *Chain, timeout time.Duration, prometheusExporterP
 
 	prometheus.MustRegister(lastClientUpdateTime)
 
-	go func() {
-		if prometheusExporterPort == "" {
-			return
-		}
-		http.Handle("/metrics", promhttp.Handler())
-		if err := http.ListenAndServe(":"+prometheusExporterPort, nil); err != nil {
-			log.Fatalf("failed to run prometheus: %v", err)
+	if prometheusExporterPort != "" {
+		if err := src.ServeMetrics(prometheusExporterPort); err != nil {
+			return err
 		}
-	}()
+	}
 
 	for {
 		var (
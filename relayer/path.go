@@ -116,6 +116,9 @@ func (p *Path) Validate() (err error) {
 	if _, err = p.GetStrategy(); err != nil {
 		return err
 	}
+	if _, err = p.Strategy.GetSweepInterval(); err != nil {
+		return err
+	}
 	if p.Src.Order != p.Dst.Order {
 		return fmt.Errorf("Both sides must have same order ('ORDERED' or 'UNORDERED'), got src(%s) and dst(%s)", p.Src.Order, p.Dst.Order)
 	}
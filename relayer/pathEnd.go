@@ -215,7 +215,7 @@ func (src *PathEnd) MsgRecvPacket(dst *PathEnd, sequence, timeoutHeight, timeout
 }
 
 // MsgTimeout creates MsgTimeout
-func (src *PathEnd) MsgTimeout(dst *PathEnd, packetData []byte, seq, timeout, timeoutStamp uint64, proof commitmenttypes.MerkleProof, proofHeight uint64, signer sdk.AccAddress) sdk.Msg {
+func (src *PathEnd) MsgTimeout(dst *PathEnd, packetData []byte, seq, nextSeqRecv, timeout, timeoutStamp uint64, proof commitmenttypes.MerkleProof, proofHeight uint64, signer sdk.AccAddress) sdk.Msg {
 	return chanTypes.NewMsgTimeout(
 		src.NewPacket(
 			dst,
@@ -224,7 +224,7 @@ func (src *PathEnd) MsgTimeout(dst *PathEnd, packetData []byte, seq, timeout, ti
 			timeout,
 			timeoutStamp,
 		),
-		seq,
+		nextSeqRecv,
 		proof,
 		proofHeight+1,
 		signer,
@@ -5,6 +5,8 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -88,17 +90,17 @@ func (c *Chain) QueryConsensusState(height int64) (*tmclient.ConsensusState, err
 	)
 
 	if height == 0 {
-		commit, err = c.Client.Commit(nil)
+		commit, err = c.RPCClient().Commit(nil)
 		if err != nil {
 			return nil, qConsStateErr(err)
 		}
-		validators, err = c.Client.Validators(nil, 1, 10000)
+		validators, err = c.RPCClient().Validators(nil, 1, 10000)
 	} else {
-		commit, err = c.Client.Commit(&height)
+		commit, err = c.RPCClient().Commit(&height)
 		if err != nil {
 			return nil, qConsStateErr(err)
 		}
-		validators, err = c.Client.Validators(nil, 1, 10000)
+		validators, err = c.RPCClient().Validators(nil, 1, 10000)
 	}
 
 	if err != nil {
@@ -563,7 +565,7 @@ func qChansErr(err error) error { return fmt.Errorf("query channels failed: %w",
 // WaitForNBlocks blocks until the next block on a given chain
 func (c *Chain) WaitForNBlocks(n int64) error {
 	var initial int64
-	h, err := c.Client.Status()
+	h, err := c.RPCClient().Status()
 	if err != nil {
 		return err
 	}
@@ -572,7 +574,7 @@ func (c *Chain) WaitForNBlocks(n int64) error {
 	}
 	initial = h.SyncInfo.LatestBlockHeight
 	for {
-		h, err = c.Client.Status()
+		h, err = c.RPCClient().Status()
 		if err != nil {
 			return err
 		}
@@ -814,10 +816,207 @@ func qPacketAckErr(err error) error {
 	return fmt.Errorf("query packet acknowledgement failed: %w", err)
 }
 
+// QueryPacketAckAbsence returns the proof that no packet acknowledgement has been written
+// for a given sequence at a given height. This is used to time out packets on unordered channels.
+func (c *Chain) QueryPacketAckAbsence(height, seq int64) (comRes CommitmentResponse, err error) {
+	if !c.PathSet() {
+		return comRes, c.ErrPathNotSet()
+	}
+
+	req := abci.RequestQuery{
+		Path:   "store/ibc/key",
+		Data:   ibctypes.KeyPacketAcknowledgement(c.PathEnd.PortID, c.PathEnd.ChannelID, uint64(seq)),
+		Height: height,
+		Prove:  true,
+	}
+
+	res, err := c.QueryABCI(req)
+	if err != nil {
+		return comRes, qPacketAckErr(err)
+	} else if res.Value != nil {
+		return comRes, qPacketAckErr(fmt.Errorf("acknowledgement exists for seq(%d)", seq))
+	}
+
+	return CommitmentResponse{
+		Proof: commitmenttypes.MerkleProof{Proof: res.Proof},
+		ProofPath: commitmenttypes.NewMerklePath(
+			strings.Split(
+				string(ibctypes.KeyPacketAcknowledgement(c.PathEnd.PortID, c.PathEnd.ChannelID, uint64(seq))),
+				"/",
+			),
+		),
+		ProofHeight: uint64(res.Height),
+	}, nil
+}
+
+// QueryPacketCommitments returns the sequences of all the packets on the configured
+// channel that have a packet commitment stored at a given height
+func (c *Chain) QueryPacketCommitments(height int64) ([]uint64, error) {
+	if !c.PathSet() {
+		return nil, c.ErrPathNotSet()
+	}
+
+	// NOTE: the key for sequence 0 is used to build the prefix shared by all packets on the channel
+	prefix := strings.TrimSuffix(ibctypes.PacketCommitmentPath(c.PathEnd.PortID, c.PathEnd.ChannelID, 0), "0")
+	seqs, err := c.querySequencesWithPrefix(height, prefix)
+	if err != nil {
+		return nil, qPacketCommitmentsErr(err)
+	}
+	return seqs, nil
+}
+
+func qPacketCommitmentsErr(err error) error {
+	return fmt.Errorf("query packet commitments failed: %w", err)
+}
+
+// QueryPacketAcks returns the sequences of all the packets on the configured
+// channel that have a packet acknowledgement stored at a given height
+func (c *Chain) QueryPacketAcks(height int64) ([]uint64, error) {
+	if !c.PathSet() {
+		return nil, c.ErrPathNotSet()
+	}
+
+	prefix := strings.TrimSuffix(ibctypes.PacketAcknowledgementPath(c.PathEnd.PortID, c.PathEnd.ChannelID, 0), "0")
+	seqs, err := c.querySequencesWithPrefix(height, prefix)
+	if err != nil {
+		return nil, qPacketAcksErr(err)
+	}
+	return seqs, nil
+}
+
+func qPacketAcksErr(err error) error {
+	return fmt.Errorf("query packet acknowledgements failed: %w", err)
+}
+
+// querySequencesWithPrefix returns the sorted sequence numbers that end the keys in the
+// ibc store starting with prefix, e.g. "commitments/ports/transfer/channels/abc/packets/"
+func (c *Chain) querySequencesWithPrefix(height int64, prefix string) ([]uint64, error) {
+	res, err := c.QueryABCI(abci.RequestQuery{
+		Path:   "store/ibc/subspace",
+		Height: height,
+		Data:   []byte(prefix),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var kvs []sdk.KVPair
+	if len(res.Value) > 0 {
+		if err = c.Amino.UnmarshalBinaryBare(res.Value, &kvs); err != nil {
+			return nil, err
+		}
+	}
+
+	seqs := make([]uint64, 0, len(kvs))
+	for _, kv := range kvs {
+		seq, err := strconv.ParseUint(strings.TrimPrefix(string(kv.Key), prefix), 10, 64)
+		if err != nil {
+			// skip any keys that don't end with a sequence number
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+
+// UnrelayedSequencesUnordered returns the unrelayed sequence numbers between two chains
+// connected by an unordered channel. A packet is unrelayed if its commitment exists on the
+// sending chain and the receiving chain has not written an acknowledgement for it.
+func UnrelayedSequencesUnordered(src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
+	pc, err := queryPacketCommitmentsAndAcks(src, dst, sh)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RelaySequences{
+		Src: missingSequences(pc.srcCommits, pc.dstAcks),
+		Dst: missingSequences(pc.dstCommits, pc.srcAcks),
+	}, nil
+}
+
+// UnrelayedAcknowledgements returns the sequence numbers of the packets that have been
+// received but whose acknowledgements have not been relayed back to the sending chain.
+// Src contains the packets sent from src and acknowledged on dst, and Dst the packets
+// sent from dst and acknowledged on src.
+func UnrelayedAcknowledgements(src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
+	pc, err := queryPacketCommitmentsAndAcks(src, dst, sh)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RelaySequences{
+		Src: receivedSequences(pc.srcCommits, pc.dstAcks),
+		Dst: receivedSequences(pc.dstCommits, pc.srcAcks),
+	}, nil
+}
+
+type packetCommitmentsAndAcks struct {
+	srcCommits, dstCommits, srcAcks, dstAcks []uint64
+}
+
+// queryPacketCommitmentsAndAcks concurrently queries the packet commitments and acks on both
+// ends of a channel at the heights in sh
+func queryPacketCommitmentsAndAcks(src, dst *Chain, sh *SyncHeaders) (*packetCommitmentsAndAcks, error) {
+	var (
+		out = &packetCommitmentsAndAcks{}
+		eg  errs
+		mtx sync.Mutex
+		wg  sync.WaitGroup
+	)
+
+	query := func(res *[]uint64, q func(int64) ([]uint64, error), h int64) {
+		defer wg.Done()
+		seqs, err := q(h)
+		mtx.Lock()
+		defer mtx.Unlock()
+		if err != nil {
+			eg = append(eg, err)
+			return
+		}
+		*res = seqs
+	}
+
+	srcH, dstH := int64(sh.GetHeight(src.ChainID)), int64(sh.GetHeight(dst.ChainID))
+	wg.Add(4)
+	go query(&out.srcCommits, src.QueryPacketCommitments, srcH)
+	go query(&out.srcAcks, src.QueryPacketAcks, srcH)
+	go query(&out.dstCommits, dst.QueryPacketCommitments, dstH)
+	go query(&out.dstAcks, dst.QueryPacketAcks, dstH)
+	wg.Wait()
+
+	return out, eg.err()
+}
+
+// missingSequences returns the sequences in seqs that are not in received
+func missingSequences(seqs, received []uint64) []uint64 {
+	return filterSequences(seqs, received, false)
+}
+
+// receivedSequences returns the sequences in seqs that are also in received
+func receivedSequences(seqs, received []uint64) []uint64 {
+	return filterSequences(seqs, received, true)
+}
+
+func filterSequences(seqs, received []uint64, keepReceived bool) []uint64 {
+	recv := make(map[uint64]struct{}, len(received))
+	for _, seq := range received {
+		recv[seq] = struct{}{}
+	}
+	out := []uint64{}
+	for _, seq := range seqs {
+		if _, ok := recv[seq]; ok == keepReceived {
+			out = append(out, seq)
+		}
+	}
+	return out
+}
+
 // PathStatus returns the status of a given path
 type PathStatus struct {
 	Chains       map[string]*ChainStatus `json:"chains" yaml:"chains"`
 	UnrelayedSeq *RelaySequences         `json:"unrelayed-seq" yaml:"unrelayed-seq"`
+	TimedOutSeq  *RelaySequences         `json:"timed-out-seq" yaml:"timed-out-seq"`
 	src          string
 	dst          string
 }
@@ -871,6 +1070,7 @@ func QueryPathStatus(src, dst *Chain, path *Path) (stat *PathStatus, err error)
 			},
 		},
 		UnrelayedSeq: &RelaySequences{},
+		TimedOutSeq:  &RelaySequences{},
 		src:          src.ChainID,
 		dst:          dst.ChainID,
 	}
@@ -939,11 +1139,17 @@ func QueryPathStatus(src, dst *Chain, path *Path) (stat *PathStatus, err error)
 	stat.Chains[dst.ChainID].Channel.State = dstChan.Channel.State.String()
 	stat.Chains[dst.ChainID].Channel.Order = dstChan.Channel.Ordering.String()
 
-	unrelayed, err := UnrelayedSequences(src, dst, sh)
+	var unrelayed *RelaySequences
+	if path.Ordered() {
+		unrelayed, err = UnrelayedSequences(src, dst, sh)
+	} else {
+		unrelayed, err = UnrelayedSequencesUnordered(src, dst, sh)
+	}
 	if err != nil {
 		return
 	}
 	stat.UnrelayedSeq = unrelayed
+	stat.TimedOutSeq = TimedOutSequences(src, dst, sh, unrelayed)
 	return
 }
 
@@ -954,7 +1160,7 @@ func (c *Chain) QueryTx(hashHex string) (sdk.TxResponse, error) {
 		return sdk.TxResponse{}, err
 	}
 
-	resTx, err := c.Client.Tx(hash, true)
+	resTx, err := c.RPCClient().Tx(hash, true)
 	if err != nil {
 		return sdk.TxResponse{}, err
 	}
@@ -988,7 +1194,7 @@ func (c *Chain) QueryTxs(height uint64, page, limit int, events []string) (*sdk.
 		return nil, errors.New("limit must greater than 0")
 	}
 
-	resTxs, err := c.Client.TxSearch(strings.Join(events, " AND "), true, page, limit, "")
+	resTxs, err := c.RPCClient().TxSearch(strings.Join(events, " AND "), true, page, limit, "")
 	if err != nil {
 		return nil, err
 	}
@@ -1022,7 +1228,7 @@ func (c *Chain) QueryABCI(req abci.RequestQuery) (res abci.ResponseQuery, err er
 		Prove:  req.Prove,
 	}
 
-	result, err := c.Client.ABCIQueryWithOptions(req.Path, req.Data, opts)
+	result, err := c.RPCClient().ABCIQueryWithOptions(req.Path, req.Data, opts)
 	if err != nil {
 		// retry queries on EOF
 		if strings.Contains(err.Error(), "EOF") {
@@ -1062,7 +1268,7 @@ func (c *Chain) QueryWithData(p string, d []byte) (byt []byte, i int64, err erro
 
 // QueryLatestHeight queries the chain for the latest height and returns it
 func (c *Chain) QueryLatestHeight() (int64, error) {
-	res, err := c.Client.Status()
+	res, err := c.RPCClient().Status()
 	if err != nil {
 		return -1, err
 	} else if res.SyncInfo.CatchingUp {
@@ -1130,12 +1336,12 @@ func (c *Chain) QueryHeaderAtHeight(height int64) (*tmclient.Header, error) {
 		return nil, fmt.Errorf("must pass in valid height, %d not valid", height)
 	}
 
-	res, err := c.Client.Commit(&height)
+	res, err := c.RPCClient().Commit(&height)
 	if err != nil {
 		return nil, err
 	}
 
-	val, err := c.Client.Validators(&height, 0, 10000)
+	val, err := c.RPCClient().Validators(&height, 0, 10000)
 	if err != nil {
 		return nil, err
 	}
@@ -1173,7 +1379,7 @@ func (c *Chain) queryBlocksForTxResults(resTxs []*ctypes.ResultTx) (map[int64]*c
 	resBlocks := make(map[int64]*ctypes.ResultBlock)
 	for _, resTx := range resTxs {
 		if _, ok := resBlocks[resTx.Height]; !ok {
-			resBlock, err := c.Client.Block(&resTx.Height)
+			resBlock, err := c.RPCClient().Block(&resTx.Height)
 			if err != nil {
 				return nil, err
 			}
@@ -3,6 +3,7 @@ package relayer
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -14,13 +15,56 @@ import (
 type RelayMsgs struct {
 	Src          []sdk.Msg
 	Dst          []sdk.Msg
-	MaxTxSize    uint64 // maximum permitted size of the msgs in a bundled relay transaction
+	MaxTxSize    uint64 // maximum permitted size of an encoded relay transaction
 	MaxMsgLength uint64 // maximum amount of messages in a bundled relay transaction
 
+	// Parallel allows batches to be sent concurrently from a chain's signers,
+	// it must only be set if the msgs can be included in any order
+	Parallel bool
+
+	// WaitForTxs makes SendSync wait for its txs to be included in a block
+	WaitForTxs bool
+
+	// Results holds the outcome of each batch sent by the last call to Send
+	Results []BatchResult
+
+	mu      sync.Mutex
 	last    bool
 	success bool
 }
 
+// BatchResult is the outcome of sending a batch of msgs to a chain in a single tx
+type BatchResult struct {
+	ChainID  string
+	Msgs     []sdk.Msg
+	TxSize   uint64 // estimated size of the encoded tx
+	Attempts int
+	Response sdk.TxResponse
+	Err      error
+}
+
+// Success returns true if the batch's tx was included in a block and executed successfully
+func (br BatchResult) Success() bool {
+	return br.Err == nil && br.Response.Code == 0
+}
+
+// batch is a set of msgs to send in a single tx along with the estimated size of the tx
+type batch struct {
+	msgs []sdk.Msg
+	size uint64
+}
+
+var (
+	// batchRetries is how many times a failed batch is resubmitted on its own
+	batchRetries = 1
+
+	// txSizeSlack is added to estimated tx sizes to account for the fee and length prefixes
+	txSizeSlack uint64 = 64
+
+	// sendBatchMsgs sends the msgs of a batch to the chain in a single tx
+	sendBatchMsgs = (*Chain).SendMsgs
+)
+
 // Ready returns true if there are messages to relay
 func (r *RelayMsgs) Ready() bool {
 	if r == nil {
@@ -33,122 +77,224 @@ func (r *RelayMsgs) Ready() bool {
 	return true
 }
 
-// Success returns the success var
+// Success returns true if all the msgs sent were included in a block and executed successfully
 func (r *RelayMsgs) Success() bool {
 	return r.success
 }
 
 func (r *RelayMsgs) IsMaxTx(msgLen, txSize uint64) bool {
+	return r.isMaxTx(msgLen, txSize, r.MaxTxSize)
+}
+
+func (r *RelayMsgs) isMaxTx(msgLen, txSize, maxTxSize uint64) bool {
 	return (r.MaxMsgLength != 0 && msgLen > r.MaxMsgLength) ||
-		(r.MaxTxSize != 0 && txSize > r.MaxTxSize)
+		(maxTxSize != 0 && txSize > maxTxSize)
 }
 
-// Send sends the messages with appropriate output
-// TODO: Parallelize? Maybe?
+// Send sends the messages with appropriate output. The msgs for src and dst are sent concurrently,
+// split into batches that fit within MaxMsgLength and the encoded size limit of each chain. A batch
+// that fails is resubmitted on its own, and the outcome of every batch is recorded in Results. If
+// Parallel is set and a chain has several signers, the batches after the first are sent to it
+// concurrently from different keys.
 func (r *RelayMsgs) Send(src, dst *Chain) {
-	var msgLen, txSize uint64
-	var msgs []sdk.Msg
-
-	r.success = true
-
 	time.Sleep(src.Delay)
 
-	// submit batches of relay transactions
-	for _, msg := range r.Src {
-		msgLen++
-		txSize += uint64(len(msg.GetSignBytes()))
+	r.Results = nil
 
-		if r.IsMaxTx(msgLen, txSize) {
-			// Submit the transactions to src chain and update its status
-			r.success = r.success && send(src, msgs)
+	var (
+		wg    sync.WaitGroup
+		dstOk bool
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dstOk = r.sendBatches(dst, r.batches(dst, r.Dst))
+	}()
+	srcOk := r.sendBatches(src, r.batches(src, r.Src))
+	wg.Wait()
 
-			// clear the current batch and reset variables
-			msgLen, txSize = 1, uint64(len(msg.GetSignBytes()))
-			msgs = []sdk.Msg{}
+	r.success = srcOk && dstOk
+}
+
+// SucceededMsgs returns the msgs sent to the chain in batches that succeeded
+func (r *RelayMsgs) SucceededMsgs(chainID string) []sdk.Msg {
+	var out []sdk.Msg
+	for _, res := range r.Results {
+		if res.ChainID == chainID && res.Success() {
+			out = append(out, res.Msgs...)
 		}
-		msgs = append(msgs, msg)
+	}
+	return out
+}
+
+// batches splits the msgs into batches that fit within MaxMsgLength and the max tx size for the chain
+func (r *RelayMsgs) batches(c *Chain, msgs []sdk.Msg) []batch {
+	if len(msgs) == 0 {
+		return nil
 	}
 
-	// submit leftover msgs
-	if len(msgs) > 0 && !send(src, msgs) {
-		r.success = false
+	maxTxSize := r.MaxTxSize
+	if chainMax, err := c.MaxTxBytes(); err != nil {
+		c.Error(fmt.Errorf("failed to query max tx bytes: %w", err))
+	} else if chainMax > 0 && (maxTxSize == 0 || chainMax < maxTxSize) {
+		maxTxSize = chainMax
 	}
 
-	// reset variables
-	msgLen, txSize = 0, 0
-	msgs = []sdk.Msg{}
+	base, sizes, err := c.encodedTxSizes(msgs)
+	if err != nil {
+		// fall back to the size of the msgs' sign bytes
+		c.Error(fmt.Errorf("failed to encode msgs to measure them: %w", err))
+		base, sizes = 0, make([]uint64, len(msgs))
+		for i, msg := range msgs {
+			sizes[i] = uint64(len(msg.GetSignBytes()))
+		}
+	}
+
+	return r.split(msgs, base, sizes, maxTxSize)
+}
 
-	for _, msg := range r.Dst {
+// split splits the msgs into batches of at most MaxMsgLength msgs and maxTxSize bytes, where a
+// tx takes base bytes plus the size of each of its msgs. A msg too large for a tx on its own is
+// sent in a batch by itself.
+func (r *RelayMsgs) split(msgs []sdk.Msg, base uint64, sizes []uint64, maxTxSize uint64) []batch {
+	var (
+		out    []batch
+		cur    batch
+		msgLen uint64
+	)
+	txSize := base
+	for i, msg := range msgs {
 		msgLen++
-		txSize += uint64(len(msg.GetSignBytes()))
+		txSize += sizes[i]
 
-		if r.IsMaxTx(msgLen, txSize) {
-			// Submit the transaction to dst chain and update its status
-			r.success = r.success && send(dst, msgs)
+		if r.isMaxTx(msgLen, txSize, maxTxSize) && len(cur.msgs) > 0 {
+			out = append(out, cur)
 
 			// clear the current batch and reset variables
-			msgLen, txSize = 1, uint64(len(msg.GetSignBytes()))
-			msgs = []sdk.Msg{}
+			msgLen, txSize = 1, base+sizes[i]
+			cur = batch{}
 		}
-		msgs = append(msgs, msg)
+		cur.msgs = append(cur.msgs, msg)
+		cur.size = txSize
 	}
 
-	// submit leftover msgs
-	if len(msgs) > 0 && !send(dst, msgs) {
-		r.success = false
+	// leftover msgs
+	if len(cur.msgs) > 0 {
+		out = append(out, cur)
 	}
+	return out
 }
 
-// Submits the messages to the provided chain and logs the result of the transaction.
-// Returns true upon success and false otherwise.
-func send(chain *Chain, msgs []sdk.Msg) bool {
-	res, err := chain.SendMsgs(msgs)
-	if err != nil || res.Code != 0 {
-		chain.LogFailedTx(res, err, msgs)
+// sendBatches sends the batches to the chain and returns true if all of them succeeded. If Parallel
+// is set, every batch after the first is sent even if an earlier one failed, otherwise sending
+// stops at the first batch that fails.
+func (r *RelayMsgs) sendBatches(chain *Chain, batches []batch) bool {
+	if len(batches) == 0 {
+		return true
+	}
+
+	// the first batch carries the update client that the proofs in the later ones are
+	// verified against, so it has to be committed before they are sent
+	if !r.sendBatch(chain, batches[0]) {
 		return false
-	} else {
-		// NOTE: Add more data to this such as identifiers
-		chain.LogSuccessTx(res, msgs)
+	}
+	batches = batches[1:]
+
+	if !r.Parallel {
+		// the msgs have to be included in order, so the ones after a failed batch would fail too
+		for _, b := range batches {
+			if !r.sendBatch(chain, b) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if len(chain.Signers()) == 1 {
+		ok := true
+		for _, b := range batches {
+			ok = r.sendBatch(chain, b) && ok
+		}
+		return ok
+	}
+
+	var wg sync.WaitGroup
+	results := make([]bool, len(batches))
+	for i, b := range batches {
+		wg.Add(1)
+		go func(i int, b batch) {
+			defer wg.Done()
+			results[i] = r.sendBatch(chain, b)
+		}(i, b)
+	}
+	wg.Wait()
+
+	for _, ok := range results {
+		if !ok {
+			return false
+		}
 	}
 	return true
 }
 
-func (r *RelayMsgs) SendSync(src, dst *Chain) {
-	var failed = false
-	time.Sleep(src.Delay)
-	// TODO: maybe figure out a better way to indicate error here?
-
-	// TODO: Parallelize? Maybe?
-	if len(r.Src) > 0 {
-		// Submit the transactions to src chain
-		res, err := src.SendMsgsSync(r.Src)
-		if err != nil || res.Code != 0 {
-			src.LogFailedTx(res, err, r.Src)
-			failed = true
-		} else {
+// sendBatch submits the batch to the chain, resubmitting it up to batchRetries times if it fails,
+// and records the result. Returns true upon success and false otherwise.
+func (r *RelayMsgs) sendBatch(chain *Chain, b batch) bool {
+	res := BatchResult{ChainID: chain.ChainID, Msgs: b.msgs, TxSize: b.size}
+	for res.Attempts <= batchRetries {
+		if res.Attempts > 0 {
+			chain.Log(fmt.Sprintf("- [%s] resubmitting batch of %d msgs", chain.ChainID, len(b.msgs)))
+		}
+		res.Attempts++
+
+		res.Response, res.Err = sendBatchMsgs(chain, b.msgs)
+		if chain.broadcastDisabled() {
+			// simulated and generated txs log their own outcome, which resubmitting wouldn't change
+			break
+		}
+		if res.Success() {
 			// NOTE: Add more data to this such as identifiers
-			src.LogSuccessTx(res, r.Src)
+			chain.LogSuccessTx(res.Response, b.msgs)
+			break
 		}
+		chain.LogFailedTx(res.Response, res.Err, b.msgs)
 	}
 
-	if len(r.Dst) > 0 {
-		// Submit the transactions to dst chain
-		res, err := dst.SendMsgsSync(r.Dst)
-		if err != nil || res.Code != 0 {
-			dst.LogFailedTx(res, err, r.Dst)
-			failed = true
-		} else {
-			// NOTE: Add more data to this such as identifiers
-			dst.LogSuccessTx(res, r.Dst)
+	r.mu.Lock()
+	r.Results = append(r.Results, res)
+	r.mu.Unlock()
+	return res.Success()
+}
+
+// SendSync sends the src and dst msgs each in a single tx broadcast in sync mode, waiting for the
+// txs to be included in a block if WaitForTxs is set. The outcome of each tx is recorded in Results.
+func (r *RelayMsgs) SendSync(src, dst *Chain) {
+	time.Sleep(src.Delay)
 
+	r.Results = nil
+	r.success = true
+	for _, side := range []struct {
+		chain *Chain
+		msgs  []sdk.Msg
+	}{{src, r.Src}, {dst, r.Dst}} {
+		if len(side.msgs) == 0 {
+			continue
 		}
-	}
 
-	if failed {
-		r.success = false
-		return
+		res := BatchResult{ChainID: side.chain.ChainID, Msgs: side.msgs, Attempts: 1}
+		res.Response, res.Err = side.chain.sendMsgs(side.msgs, side.chain.BroadcastTxSync, r.WaitForTxs)
+		switch {
+		case side.chain.broadcastDisabled():
+			r.success = r.success && res.Success()
+		case res.Success():
+			// NOTE: Add more data to this such as identifiers
+			side.chain.LogSuccessTx(res.Response, side.msgs)
+		default:
+			side.chain.LogFailedTx(res.Response, res.Err, side.msgs)
+			r.success = false
+		}
+		r.Results = append(r.Results, res)
 	}
-	r.success = true
 }
 
 func getMsgAction(msgs []sdk.Msg) string {
@@ -0,0 +1,199 @@
+package relayer
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// sizedMsg is a msg that only identifies itself, batches are split by the sizes given next to it
+type sizedMsg int
+
+func (m sizedMsg) Route() string                { return "test" }
+func (m sizedMsg) Type() string                 { return "sized" }
+func (m sizedMsg) ValidateBasic() error         { return nil }
+func (m sizedMsg) GetSignBytes() []byte         { return nil }
+func (m sizedMsg) GetSigners() []sdk.AccAddress { return nil }
+
+func TestRelayMsgsSplit(t *testing.T) {
+	msgs := func(n int) []sdk.Msg {
+		out := make([]sdk.Msg, n)
+		for i := range out {
+			out[i] = sizedMsg(i)
+		}
+		return out
+	}
+	tests := []struct {
+		name         string
+		maxMsgLength uint64
+		maxTxSize    uint64
+		base         uint64
+		sizes        []uint64
+		want         [][]int  // indexes of the msgs of each batch
+		wantSizes    []uint64 // encoded size of each batch
+	}{
+		{"no msgs", 0, 0, 10, nil, nil, nil},
+		{"no limits", 0, 0, 10, []uint64{5, 5, 5}, [][]int{{0, 1, 2}}, []uint64{25}},
+		{"at the msg limit", 3, 0, 10, []uint64{5, 5, 5}, [][]int{{0, 1, 2}}, []uint64{25}},
+		{"over the msg limit", 2, 0, 10, []uint64{5, 5, 5, 5, 5}, [][]int{{0, 1}, {2, 3}, {4}}, []uint64{20, 20, 15}},
+		{"one msg each", 1, 0, 10, []uint64{5, 5}, [][]int{{0}, {1}}, []uint64{15, 15}},
+		{"at the size limit", 0, 25, 10, []uint64{5, 5, 5}, [][]int{{0, 1, 2}}, []uint64{25}},
+		{"over the size limit", 0, 24, 10, []uint64{5, 5, 5}, [][]int{{0, 1}, {2}}, []uint64{20, 15}},
+		{"base counts in every batch", 0, 20, 10, []uint64{4, 4, 4, 4}, [][]int{{0, 1}, {2, 3}}, []uint64{18, 18}},
+		{"uneven sizes", 0, 30, 0, []uint64{10, 25, 5, 5, 20}, [][]int{{0}, {1, 2}, {3, 4}}, []uint64{10, 30, 25}},
+		{"oversized msg goes alone", 0, 20, 10, []uint64{5, 50, 5}, [][]int{{0}, {1}, {2}}, []uint64{15, 60, 15}},
+		{"oversized first msg", 0, 20, 10, []uint64{50, 5, 5}, [][]int{{0}, {1, 2}}, []uint64{60, 20}},
+		{"size limit before msg limit", 3, 20, 10, []uint64{5, 5, 5, 1}, [][]int{{0, 1}, {2, 3}}, []uint64{20, 16}},
+		{"msg limit before size limit", 2, 100, 10, []uint64{5, 5, 5}, [][]int{{0, 1}, {2}}, []uint64{20, 15}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &RelayMsgs{MaxMsgLength: tc.maxMsgLength}
+			got := r.split(msgs(len(tc.sizes)), tc.base, tc.sizes, tc.maxTxSize)
+			require.Len(t, got, len(tc.want))
+			for i, b := range got {
+				idx := make([]int, len(b.msgs))
+				for j, msg := range b.msgs {
+					idx[j] = int(msg.(sizedMsg))
+				}
+				require.Equal(t, tc.want[i], idx, "batch %d", i)
+				require.Equal(t, tc.wantSizes[i], b.size, "size of batch %d", i)
+			}
+		})
+	}
+}
+
+func TestRelayMsgsIsMaxTx(t *testing.T) {
+	tests := []struct {
+		name                      string
+		maxMsgLength              uint64
+		msgLen, txSize, maxTxSize uint64
+		want                      bool
+	}{
+		{"no limits", 0, 100, 1 << 20, 0, false},
+		{"under both", 5, 5, 100, 100, false},
+		{"over the msg length", 5, 6, 100, 100, true},
+		{"over the tx size", 5, 5, 101, 100, true},
+		{"tx size without msg length", 0, 100, 101, 100, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &RelayMsgs{MaxMsgLength: tc.maxMsgLength}
+			require.Equal(t, tc.want, r.isMaxTx(tc.msgLen, tc.txSize, tc.maxTxSize))
+		})
+	}
+}
+
+// stubSender stands in for broadcasting the txs of batches. A tx fails while any of its msgs
+// has failures left, -1 failing it every time, and the msgs of every tx it's given are recorded.
+type stubSender struct {
+	mu    sync.Mutex
+	fails map[sizedMsg]int
+	sent  [][]int
+}
+
+func (s *stubSender) send(c *Chain, msgs []sdk.Msg) (sdk.TxResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]int, len(msgs))
+	failed := false
+	for i, msg := range msgs {
+		ids[i] = int(msg.(sizedMsg))
+		if n := s.fails[msg.(sizedMsg)]; n != 0 {
+			failed = true
+			if n > 0 {
+				s.fails[msg.(sizedMsg)]--
+			}
+		}
+	}
+	s.sent = append(s.sent, ids)
+	if failed {
+		return sdk.TxResponse{Codespace: "sdk", Code: 11}, nil
+	}
+	return sdk.TxResponse{TxHash: fmt.Sprint(ids)}, nil
+}
+
+func (s *stubSender) use() func() {
+	send := sendBatchMsgs
+	sendBatchMsgs = s.send
+	return func() { sendBatchMsgs = send }
+}
+
+func TestRelayMsgsSendBatches(t *testing.T) {
+	batches := []batch{
+		{msgs: []sdk.Msg{sizedMsg(0), sizedMsg(1)}},
+		{msgs: []sdk.Msg{sizedMsg(2)}},
+		{msgs: []sdk.Msg{sizedMsg(3), sizedMsg(4)}},
+	}
+	tests := []struct {
+		name     string
+		parallel bool
+		fails    map[sizedMsg]int
+		ok       bool
+		sent     [][]int // the msgs of each tx in the order they're sent
+		attempts []int   // the attempts recorded for each batch that was sent
+		failed   int     // the batches that failed, -1 if none did
+	}{
+		{"in order", false, nil, true, [][]int{{0, 1}, {2}, {3, 4}}, []int{1, 1, 1}, -1},
+		{"retried once", false, map[sizedMsg]int{2: 1}, true, [][]int{{0, 1}, {2}, {2}, {3, 4}}, []int{1, 2, 1}, -1},
+		{"ordered stops at a failed batch", false, map[sizedMsg]int{2: -1}, false, [][]int{{0, 1}, {2}, {2}}, []int{1, 2}, 1},
+		{"unordered sends the rest", true, map[sizedMsg]int{2: -1}, false, [][]int{{0, 1}, {2}, {2}, {3, 4}}, []int{1, 2, 1}, 1},
+		{"unordered waits for the first batch", true, map[sizedMsg]int{0: -1}, false, [][]int{{0, 1}, {0, 1}}, []int{2}, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &stubSender{fails: make(map[sizedMsg]int)}
+			for msg, n := range tc.fails {
+				stub.fails[msg] = n
+			}
+			defer stub.use()()
+
+			c := &Chain{ChainID: "ibc0", logger: log.NewNopLogger()}
+			r := &RelayMsgs{Parallel: tc.parallel}
+			require.Equal(t, tc.ok, r.sendBatches(c, batches))
+			require.Equal(t, tc.sent, stub.sent)
+
+			var succeeded []sdk.Msg
+			require.Len(t, r.Results, len(tc.attempts))
+			for i, res := range r.Results {
+				require.Equal(t, "ibc0", res.ChainID)
+				require.Equal(t, batches[i].msgs, res.Msgs)
+				require.Equal(t, tc.attempts[i], res.Attempts, "attempts of batch %d", i)
+				require.Equal(t, i != tc.failed, res.Success(), "success of batch %d", i)
+				if res.Success() {
+					succeeded = append(succeeded, res.Msgs...)
+				}
+			}
+			require.Equal(t, succeeded, r.SucceededMsgs("ibc0"))
+		})
+	}
+}
+
+func TestRelayMsgsSendBatchesFromSigners(t *testing.T) {
+	stub := &stubSender{fails: map[sizedMsg]int{2: -1}}
+	defer stub.use()()
+
+	batches := []batch{
+		{msgs: []sdk.Msg{sizedMsg(0)}},
+		{msgs: []sdk.Msg{sizedMsg(1)}},
+		{msgs: []sdk.Msg{sizedMsg(2)}},
+		{msgs: []sdk.Msg{sizedMsg(3)}},
+	}
+	c := &Chain{ChainID: "ibc0", Keys: []string{"a", "b"}, KeySelection: KeySelectionRoundRobin, logger: log.NewNopLogger()}
+	r := &RelayMsgs{Parallel: true}
+	require.False(t, r.sendBatches(c, batches))
+
+	// the first batch is sent on its own, the rest concurrently and each failed one twice
+	require.Equal(t, []int{0}, stub.sent[0])
+	require.ElementsMatch(t, [][]int{{1}, {2}, {2}, {3}}, stub.sent[1:])
+	require.Len(t, r.Results, 4)
+	require.ElementsMatch(t, []sdk.Msg{sizedMsg(0), sizedMsg(1), sizedMsg(3)}, r.SucceededMsgs("ibc0"))
+}
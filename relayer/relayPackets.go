@@ -6,6 +6,8 @@ import (
 	retry "github.com/avast/retry-go"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	chanTypes "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/types"
+	tmclient "github.com/cosmos/cosmos-sdk/x/ibc/07-tendermint/types"
+	ibctypes "github.com/cosmos/cosmos-sdk/x/ibc/types"
 )
 
 type relayPacket interface {
@@ -21,7 +23,8 @@ type relayMsgTimeout struct {
 	seq          uint64
 	timeout      uint64
 	timeoutStamp uint64
-	dstRecvRes   *chanTypes.RecvResponse
+	nextSeqRecv  uint64
+	dstProofRes  *CommitmentResponse
 
 	pass bool
 }
@@ -38,17 +41,34 @@ func (rp *relayMsgTimeout) Timeout() uint64 {
 	return rp.timeout
 }
 
+// FetchCommitResponse fetches the proof from dst that the packet has not been received.
+// On ordered channels this is the proof of dst's next receive sequence and on unordered
+// channels it is the proof that no acknowledgement has been written for the packet.
 func (rp *relayMsgTimeout) FetchCommitResponse(src, dst *Chain, sh *SyncHeaders) (err error) {
-	var dstRecvRes chanTypes.RecvResponse
+	var (
+		dstProofRes CommitmentResponse
+		nextSeqRecv = rp.seq
+		height      = int64(sh.GetHeight(dst.ChainID) - 1)
+	)
 
 	// retry getting commit response until it succeeds
 	if err = retry.Do(func() error {
-		// NOTE: Timeouts currently only work with ORDERED channels for nwo
-		dstRecvRes, err = dst.QueryNextSeqRecv(int64(sh.GetHeight(dst.ChainID) - 1))
-		if err != nil {
-			return err
-		} else if dstRecvRes.Proof.Proof == nil {
-			return fmt.Errorf("- [%s]@{%d} - Packet Commitment Proof is nil seq(%d)", dst.ChainID, int64(sh.GetHeight(dst.ChainID)-1), rp.seq)
+		if dst.PathEnd.getOrder() == ibctypes.UNORDERED {
+			dstProofRes, err = dst.QueryPacketAckAbsence(height, int64(rp.seq))
+			if err != nil {
+				return err
+			}
+		} else {
+			var dstRecvRes chanTypes.RecvResponse
+			dstRecvRes, err = dst.QueryNextSeqRecv(height)
+			if err != nil {
+				return err
+			}
+			dstProofRes = CommitmentResponse{Proof: dstRecvRes.Proof, ProofHeight: dstRecvRes.ProofHeight}
+			nextSeqRecv = dstRecvRes.NextSequenceRecv
+		}
+		if dstProofRes.Proof.Proof == nil {
+			return fmt.Errorf("- [%s]@{%d} - Packet Timeout Proof is nil seq(%d)", dst.ChainID, height, rp.seq)
 		}
 		return nil
 	}); err != nil {
@@ -56,22 +76,28 @@ func (rp *relayMsgTimeout) FetchCommitResponse(src, dst *Chain, sh *SyncHeaders)
 		return
 	}
 
-	rp.dstRecvRes = &dstRecvRes
+	if nextSeqRecv > rp.seq {
+		return fmt.Errorf("- [%s]@{%d} - packet seq(%d) already received, can't time it out", dst.ChainID, height, rp.seq)
+	}
+
+	rp.nextSeqRecv = nextSeqRecv
+	rp.dstProofRes = &dstProofRes
 	return
 }
 
 func (rp *relayMsgTimeout) Msg(src, dst *Chain) sdk.Msg {
-	if rp.dstRecvRes == nil {
+	if rp.dstProofRes == nil {
 		return nil
 	}
 	return src.PathEnd.MsgTimeout(
 		dst.PathEnd,
 		rp.packetData,
 		rp.seq,
+		rp.nextSeqRecv,
 		rp.timeout,
 		rp.timeoutStamp,
-		rp.dstRecvRes.Proof,
-		rp.dstRecvRes.ProofHeight,
+		rp.dstProofRes.Proof,
+		rp.dstProofRes.ProofHeight,
 		src.MustGetAddress(),
 	)
 }
@@ -92,11 +118,20 @@ func (rp *relayMsgRecvPacket) timeoutPacket() *relayMsgTimeout {
 		seq:          rp.seq,
 		timeout:      rp.timeout,
 		timeoutStamp: rp.timeoutStamp,
-		dstRecvRes:   nil,
 		pass:         false,
 	}
 }
 
+// timedOut returns true if the packet can no longer be received on the chain
+// with the given latest header
+func (rp *relayMsgRecvPacket) timedOut(dstHeader *tmclient.Header) bool {
+	if dstHeader == nil || dstHeader.Header == nil {
+		return false
+	}
+	return (rp.timeout != 0 && dstHeader.GetHeight() >= rp.timeout) ||
+		(rp.timeoutStamp != 0 && uint64(dstHeader.Time.UnixNano()) >= rp.timeoutStamp)
+}
+
 func (rp *relayMsgRecvPacket) Data() []byte {
 	return rp.packetData
 }
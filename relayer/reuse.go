@@ -0,0 +1,211 @@
+package relayer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	clientExported "github.com/cosmos/cosmos-sdk/x/ibc/02-client/exported"
+	tmclient "github.com/cosmos/cosmos-sdk/x/ibc/07-tendermint/types"
+	ibctypes "github.com/cosmos/cosmos-sdk/x/ibc/types"
+)
+
+// activeClient returns the client state as a tendermint client and true if it tracks the chain,
+// isn't frozen and its trusting period hasn't expired, so that it can still be updated
+func activeClient(cs clientExported.ClientState, chainID string) (tmclient.ClientState, bool) {
+	// TODO: support other client types through a switch here as they become available
+	clnt, ok := cs.(tmclient.ClientState)
+	if !ok || clnt.LastHeader.Commit == nil || clnt.LastHeader.Header == nil {
+		return clnt, false
+	}
+	return clnt, clnt.GetChainID() == chainID && !clnt.IsFrozen() &&
+		time.Since(clnt.GetLatestTimestamp()) < clnt.TrustingPeriod
+}
+
+// QueryActiveClients returns the identifiers of the active clients of the counterparty chain on
+// the chain, the most recently updated first
+func (c *Chain) QueryActiveClients(counterpartyChainID string) ([]string, error) {
+	clients, err := c.QueryClients(1, 1000)
+	if err != nil {
+		return nil, err
+	}
+
+	var active []tmclient.ClientState
+	for _, cs := range clients {
+		if clnt, ok := activeClient(cs, counterpartyChainID); ok {
+			active = append(active, clnt)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].GetLatestTimestamp().After(active[j].GetLatestTimestamp())
+	})
+
+	out := make([]string, len(active))
+	for i, clnt := range active {
+		out[i] = clnt.GetID()
+	}
+	return out, nil
+}
+
+// ReuseIdentifiers replaces the identifiers of the path with those of active clients that already
+// exist between the chains of src and dst, along with those of an open connection over the clients
+// and an open channel over the connection between the path's ports. The path's own clients are
+// preferred if they are active. When the clients or the connection are replaced but nothing open is
+// found over them, new connection and channel identifiers are generated for the handshakes to use.
+func (p *Path) ReuseIdentifiers(src, dst *Chain) error {
+	srcClients, err := src.QueryActiveClients(dst.ChainID)
+	if err != nil {
+		return err
+	}
+	dstClients, err := dst.QueryActiveClients(src.ChainID)
+	if err != nil {
+		return err
+	}
+	srcClients = preferID(srcClients, p.Src.ClientID)
+	dstClients = preferID(dstClients, p.Dst.ClientID)
+
+	srcClient, srcConn, dstClient, dstConn, err := findOpenConnection(src, dst, srcClients, dstClients)
+	if err != nil {
+		return err
+	}
+	if srcConn == "" && len(srcClients) > 0 {
+		srcClient = srcClients[0]
+	}
+	if srcConn == "" && len(dstClients) > 0 {
+		dstClient = dstClients[0]
+	}
+
+	replaced := (srcClient != "" && srcClient != p.Src.ClientID) || (dstClient != "" && dstClient != p.Dst.ClientID)
+	if srcClient != "" {
+		p.Src.ClientID = srcClient
+		src.Log(fmt.Sprintf("- [%s]client{%s} of [%s] is active, reusing it", src.ChainID, srcClient, dst.ChainID))
+	}
+	if dstClient != "" {
+		p.Dst.ClientID = dstClient
+		dst.Log(fmt.Sprintf("- [%s]client{%s} of [%s] is active, reusing it", dst.ChainID, dstClient, src.ChainID))
+	}
+	if srcConn == "" {
+		if replaced {
+			// the path's connection may already exist over the clients it had
+			p.Src.ConnectionID, p.Dst.ConnectionID = RandLowerCaseLetterString(10), RandLowerCaseLetterString(10)
+			p.Src.ChannelID, p.Dst.ChannelID = RandLowerCaseLetterString(10), RandLowerCaseLetterString(10)
+		}
+		return nil
+	}
+
+	replaced = srcConn != p.Src.ConnectionID || dstConn != p.Dst.ConnectionID
+	p.Src.ConnectionID, p.Dst.ConnectionID = srcConn, dstConn
+	src.Log(fmt.Sprintf("- [%s]conn{%s} and [%s]conn{%s} are open, reusing them",
+		src.ChainID, srcConn, dst.ChainID, dstConn))
+
+	srcChan, dstChan, err := findOpenChannel(src, dst, p)
+	if err != nil {
+		return err
+	} else if srcChan == "" {
+		if replaced {
+			p.Src.ChannelID, p.Dst.ChannelID = RandLowerCaseLetterString(10), RandLowerCaseLetterString(10)
+		}
+		return nil
+	}
+	p.Src.ChannelID, p.Dst.ChannelID = srcChan, dstChan
+	src.Log(fmt.Sprintf("- [%s]chan{%s}port{%s} and [%s]chan{%s}port{%s} are open, reusing them",
+		src.ChainID, srcChan, p.Src.PortID, dst.ChainID, dstChan, p.Dst.PortID))
+	return nil
+}
+
+// findOpenConnection returns the identifiers of the first connection that is open on both chains
+// between one of the clients on src and one of the clients on dst, or empty strings if there is none
+func findOpenConnection(src, dst *Chain, srcClients, dstClients []string) (
+	srcClient, srcConn, dstClient, dstConn string, err error) {
+	for _, clientID := range srcClients {
+		s, err := src.withPath(&PathEnd{ChainID: src.ChainID, ClientID: clientID,
+			ConnectionID: dcon, ChannelID: dcha, PortID: dpor, Order: "ORDERED"})
+		if err != nil {
+			return "", "", "", "", err
+		}
+
+		conns, err := s.QueryConnectionsUsingClient(0)
+		if err != nil {
+			return "", "", "", "", err
+		}
+
+		for _, connID := range conns.ConnectionPaths {
+			s.PathEnd.ConnectionID = connID
+			srcEnd, err := s.QueryConnection(0)
+			if err != nil {
+				return "", "", "", "", err
+			}
+			cp := srcEnd.Connection.Counterparty
+			if srcEnd.Connection.State != ibctypes.OPEN || !containsID(dstClients, cp.ClientID) {
+				continue
+			}
+
+			d, err := dst.withPath(&PathEnd{ChainID: dst.ChainID, ClientID: cp.ClientID,
+				ConnectionID: cp.ConnectionID, ChannelID: dcha, PortID: dpor, Order: "ORDERED"})
+			if err != nil {
+				// the counterparty connection identifier isn't one the relayer can use
+				continue
+			}
+			dstEnd, err := d.QueryConnection(0)
+			if err != nil {
+				return "", "", "", "", err
+			}
+			if dstEnd.Connection.State == ibctypes.OPEN && dstEnd.Connection.ClientID == cp.ClientID &&
+				dstEnd.Connection.Counterparty.ClientID == clientID &&
+				dstEnd.Connection.Counterparty.ConnectionID == connID {
+				return clientID, connID, cp.ClientID, cp.ConnectionID, nil
+			}
+		}
+	}
+	return "", "", "", "", nil
+}
+
+// findOpenChannel returns the identifiers of a channel that is open on both chains over the path's
+// connection between its ports with its ordering, or empty strings if there is none. The path's
+// own channel is preferred if it is one of them.
+func findOpenChannel(src, dst *Chain, p *Path) (srcChan, dstChan string, err error) {
+	srcChans, err := src.QueryConnectionChannels(p.Src.ConnectionID, 1, 1000)
+	if err != nil {
+		return "", "", err
+	}
+	dstChans, err := dst.QueryConnectionChannels(p.Dst.ConnectionID, 1, 1000)
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, sc := range srcChans {
+		if sc.State != ibctypes.OPEN || sc.PortID != p.Src.PortID || sc.Ordering != p.Src.getOrder() ||
+			sc.Counterparty.PortID != p.Dst.PortID {
+			continue
+		}
+		for _, dc := range dstChans {
+			if dc.State == ibctypes.OPEN && dc.ID == sc.Counterparty.ChannelID && dc.PortID == p.Dst.PortID &&
+				dc.Counterparty.ChannelID == sc.ID && dc.Counterparty.PortID == sc.PortID {
+				if srcChan == "" || sc.ID == p.Src.ChannelID {
+					srcChan, dstChan = sc.ID, dc.ID
+				}
+			}
+		}
+	}
+	return srcChan, dstChan, nil
+}
+
+// preferID moves the identifier to the front of the ids if it is one of them
+func preferID(ids []string, id string) []string {
+	for i := range ids {
+		if ids[i] == id {
+			out := append([]string{id}, ids[:i]...)
+			return append(out, ids[i+1:]...)
+		}
+	}
+	return ids
+}
+
+func containsID(ids []string, id string) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
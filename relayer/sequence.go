@@ -0,0 +1,94 @@
+package relayer
+
+import (
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+)
+
+// sequenceTracker hands out the account sequences of a chain's keys locally, so that txs
+// can be signed and broadcast concurrently without waiting for the previous ones to be
+// committed. The sequences are synced from the chain on first use and after a tx is rejected.
+type sequenceTracker struct {
+	mu       sync.Mutex
+	accounts map[string]*accountSequence // keyed by the raw address bytes
+}
+
+type accountSequence struct {
+	synced bool
+	number uint64
+	next   uint64
+}
+
+func newSequenceTracker() *sequenceTracker {
+	return &sequenceTracker{accounts: make(map[string]*accountSequence)}
+}
+
+// next returns the account number and the sequence to sign the next tx from addr with
+func (st *sequenceTracker) next(c *Chain, addr sdk.AccAddress) (number, sequence uint64, err error) {
+	return st.get(c, addr, true)
+}
+
+// current returns the account number and the next sequence of addr without handing it out
+func (st *sequenceTracker) current(c *Chain, addr sdk.AccAddress) (number, sequence uint64, err error) {
+	return st.get(c, addr, false)
+}
+
+func (st *sequenceTracker) get(c *Chain, addr sdk.AccAddress, increment bool) (uint64, uint64, error) {
+	if st == nil {
+		return queryAccount(c, addr)
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	acc, ok := st.accounts[string(addr)]
+	if !ok {
+		acc = &accountSequence{}
+		st.accounts[string(addr)] = acc
+	}
+
+	if !acc.synced {
+		number, sequence, err := queryAccount(c, addr)
+		if err != nil {
+			return 0, 0, err
+		}
+		acc.number, acc.next, acc.synced = number, sequence, true
+	}
+
+	sequence := acc.next
+	if increment {
+		acc.next++
+	}
+	return acc.number, sequence, nil
+}
+
+// resync makes the next sequence of addr be queried from the chain again
+func (st *sequenceTracker) resync(addr sdk.AccAddress) {
+	if st == nil {
+		return
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if acc, ok := st.accounts[string(addr)]; ok {
+		acc.synced = false
+	}
+}
+
+// broadcastResult resyncs the sequence of addr if the tx it signed didn't make it into the
+// mempool, since its sequence wasn't used and the sequences handed out after it won't match
+func (st *sequenceTracker) broadcastResult(addr sdk.AccAddress, res sdk.TxResponse, err error) {
+	if err != nil || (res.Code != 0 && res.Height == 0) {
+		st.resync(addr)
+	}
+}
+
+func queryAccount(c *Chain, addr sdk.AccAddress) (number, sequence uint64, err error) {
+	acc, err := auth.NewAccountRetriever(c.Cdc, c).GetAccount(addr)
+	if err != nil {
+		return 0, 0, err
+	}
+	return acc.GetAccountNumber(), acc.GetSequence(), nil
+}
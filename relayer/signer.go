@@ -0,0 +1,148 @@
+package relayer
+
+import (
+	"fmt"
+	"sync"
+
+	keys "github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chanTypes "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/types"
+	tmclient "github.com/cosmos/cosmos-sdk/x/ibc/07-tendermint/types"
+)
+
+const (
+	// KeySelectionRoundRobin signs each tx with the next of the chain's keys in turn
+	KeySelectionRoundRobin = "round-robin"
+
+	// KeySelectionLeastLoaded signs each tx with the chain's key that has the fewest txs being broadcast
+	KeySelectionLeastLoaded = "least-loaded"
+)
+
+// signerPool selects which of a chain's keys signs each relay tx. Every key has its own
+// account sequence in the chain's sequenceTracker, so txs signed by different keys can
+// be broadcast in parallel without their sequences colliding.
+type signerPool struct {
+	mu       sync.Mutex
+	mode     string
+	next     int
+	inflight map[string]int
+}
+
+func newSignerPool(mode string) *signerPool {
+	return &signerPool{mode: mode, inflight: make(map[string]int)}
+}
+
+func validateKeySelection(mode string) error {
+	switch mode {
+	case "", KeySelectionRoundRobin, KeySelectionLeastLoaded:
+		return nil
+	default:
+		return fmt.Errorf("invalid key-selection %s, expected %s or %s", mode, KeySelectionRoundRobin, KeySelectionLeastLoaded)
+	}
+}
+
+// Signers returns the names of the keys that sign the chain's relay txs. Without a
+// KeySelection only the chain's Key is used, otherwise its Keys are, or its Key if it
+// has no Keys.
+func (src *Chain) Signers() []string {
+	if src.KeySelection == "" || len(src.Keys) == 0 {
+		return []string{src.Key}
+	}
+	return src.Keys
+}
+
+// acquire returns the key to sign the next tx with and a function
+// to call once the tx has been broadcast
+func (sp *signerPool) acquire(names []string) (string, func()) {
+	if sp == nil || len(names) == 1 {
+		return names[0], func() {}
+	}
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	var name string
+	switch sp.mode {
+	case KeySelectionLeastLoaded:
+		name = names[0]
+		for _, n := range names[1:] {
+			if sp.inflight[n] < sp.inflight[name] {
+				name = n
+			}
+		}
+	default:
+		name = names[sp.next%len(names)]
+		sp.next++
+	}
+
+	sp.inflight[name]++
+	return name, func() {
+		sp.mu.Lock()
+		defer sp.mu.Unlock()
+		sp.inflight[name]--
+	}
+}
+
+// acquireSigner picks the key to sign msgs with. If the msgs are all relay msgs signed by
+// the chain's Key, they are returned signed by a key from the pool instead. Other msgs are
+// signed by the chain's Key.
+func (src *Chain) acquireSigner(msgs []sdk.Msg) (keys.Info, []sdk.Msg, func(), error) {
+	addr, err := src.GetAddress()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	signers := src.Signers()
+	if len(signers) == 1 || !resignable(msgs, addr) {
+		info, err := src.Keybase.Key(src.Key)
+		return info, msgs, func() {}, err
+	}
+
+	name, release := src.signers.acquire(signers)
+	info, err := src.Keybase.Key(name)
+	if err != nil {
+		release()
+		return nil, nil, nil, err
+	}
+
+	out := make([]sdk.Msg, len(msgs))
+	for i, msg := range msgs {
+		out[i] = withSigner(msg, info.GetAddress())
+	}
+	return info, out, release, nil
+}
+
+// resignable returns true if all the msgs are relay msgs signed by addr
+func resignable(msgs []sdk.Msg, addr sdk.AccAddress) bool {
+	for _, msg := range msgs {
+		switch msg.(type) {
+		case tmclient.MsgUpdateClient, chanTypes.MsgPacket, chanTypes.MsgTimeout, chanTypes.MsgAcknowledgement:
+		default:
+			return false
+		}
+		if signers := msg.GetSigners(); len(signers) != 1 || !signers[0].Equals(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// withSigner returns a copy of the relay msg signed by addr
+func withSigner(msg sdk.Msg, addr sdk.AccAddress) sdk.Msg {
+	switch m := msg.(type) {
+	case tmclient.MsgUpdateClient:
+		m.Signer = addr
+		return m
+	case chanTypes.MsgPacket:
+		m.Signer = addr
+		return m
+	case chanTypes.MsgTimeout:
+		m.Signer = addr
+		return m
+	case chanTypes.MsgAcknowledgement:
+		m.Signer = addr
+		return m
+	default:
+		return msg
+	}
+}
@@ -0,0 +1,325 @@
+package relayer
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// pendingTxTTL is how long a tx that hasn't been committed is assumed to still be in the mempool
+var pendingTxTTL = 10 * time.Minute
+
+// RelayStore persists the relay transactions that have been broadcast but not yet seen
+// committed, along with the packets they relay. A restarted relayer reconciles it with
+// the chains so that it doesn't resubmit msgs that are still waiting in a mempool.
+type RelayStore struct {
+	mu sync.Mutex
+	db dbm.DB
+}
+
+// PendingTx is a relay transaction that was broadcast to a chain and isn't known to be committed
+type PendingTx struct {
+	ChainID  string          `json:"chain-id"`
+	TxHash   string          `json:"tx-hash"`
+	Signer   string          `json:"signer"`
+	Sequence uint64          `json:"sequence"`
+	Packets  []PendingPacket `json:"packets,omitempty"`
+	Time     time.Time       `json:"time"`
+}
+
+// PendingPacket identifies a packet relayed by a PendingTx by its source port, channel and sequence
+type PendingPacket struct {
+	MsgType   string `json:"msg-type"`
+	PortID    string `json:"port-id"`
+	ChannelID string `json:"channel-id"`
+	Sequence  uint64 `json:"sequence"`
+}
+
+func storeDir(home string) string {
+	return path.Join(home, "state")
+}
+
+// OpenRelayStore opens the relay state store of the named path under the home directory,
+// creating it if needed. Each path has its own store so that the paths can be relayed by
+// different processes, as a store can only be opened by one at a time.
+// CONTRACT: must close the store when done with it
+func OpenRelayStore(home, name string) (*RelayStore, error) {
+	db, err := dbm.NewGoLevelDB(name, storeDir(home))
+	if err != nil {
+		return nil, fmt.Errorf("can't open relay state store: %w", err)
+	}
+	return &RelayStore{db: db}, nil
+}
+
+// Close closes the underlying database
+func (rs *RelayStore) Close() error {
+	return rs.db.Close()
+}
+
+func txKey(chainID, hash string) []byte {
+	return []byte(fmt.Sprintf("tx/%s/%s", chainID, hash))
+}
+
+func packetKey(chainID, portID, channelID string, seq uint64) []byte {
+	return []byte(fmt.Sprintf("packet/%s/%s/%s/%d", chainID, portID, channelID, seq))
+}
+
+// AddPendingTx records a tx before it is broadcast
+func (rs *RelayStore) AddPendingTx(tx PendingTx) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	bz, err := json.Marshal(tx)
+	if err != nil {
+		return err
+	}
+
+	batch := rs.db.NewBatch()
+	defer batch.Close()
+	batch.Set(txKey(tx.ChainID, tx.TxHash), bz)
+	for _, p := range tx.Packets {
+		batch.Set(packetKey(tx.ChainID, p.PortID, p.ChannelID, p.Sequence), []byte(tx.TxHash))
+	}
+	return batch.WriteSync()
+}
+
+// RemovePendingTx forgets a tx once it is known to be committed or dropped
+func (rs *RelayStore) RemovePendingTx(tx PendingTx) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	batch := rs.db.NewBatch()
+	defer batch.Close()
+	batch.Delete(txKey(tx.ChainID, tx.TxHash))
+	for _, p := range tx.Packets {
+		// only remove the packet if a later tx hasn't relayed it again
+		key := packetKey(tx.ChainID, p.PortID, p.ChannelID, p.Sequence)
+		hash, err := rs.db.Get(key)
+		if err != nil {
+			return err
+		}
+		if string(hash) == tx.TxHash {
+			batch.Delete(key)
+		}
+	}
+	return batch.WriteSync()
+}
+
+// PendingTxs returns the pending txs recorded for the chain
+func (rs *RelayStore) PendingTxs(chainID string) ([]PendingTx, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	iter, err := dbm.IteratePrefix(rs.db, []byte(fmt.Sprintf("tx/%s/", chainID)))
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var out []PendingTx
+	for ; iter.Valid(); iter.Next() {
+		var tx PendingTx
+		if err = json.Unmarshal(iter.Value(), &tx); err != nil {
+			return nil, err
+		}
+		out = append(out, tx)
+	}
+	return out, nil
+}
+
+// PendingPacket returns true if a pending tx on the chain relays the packet
+// with the given source port, channel and sequence
+func (rs *RelayStore) PendingPacket(chainID, portID, channelID string, seq uint64) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	has, err := rs.db.Has(packetKey(chainID, portID, channelID, seq))
+	return err == nil && has
+}
+
+// Reconcile checks the pending txs recorded for the chain against its state. Txs that were
+// committed, or that can no longer be because their sequence has been used or they have
+// been pending for longer than pendingTxTTL, are removed so their packets are relayed
+// again if they still need to be.
+func (rs *RelayStore) Reconcile(c *Chain) error {
+	txs, err := rs.PendingTxs(c.ChainID)
+	if err != nil || len(txs) == 0 {
+		return err
+	}
+
+	// query the account sequences before the txs, so a sequence that has moved past
+	// a tx that isn't found means the tx can't be committed anymore
+	sequences := make(map[string]uint64)
+	for _, tx := range txs {
+		if _, ok := sequences[tx.Signer]; ok {
+			continue
+		}
+		if sequences[tx.Signer], err = c.queryAccountSequence(tx.Signer); err != nil {
+			return err
+		}
+	}
+
+	var committed, dropped int
+	for _, tx := range txs {
+		_, err = c.QueryTx(tx.TxHash)
+		switch {
+		case err == nil:
+			committed++
+		case !txNotFound(err):
+			return err
+		case sequences[tx.Signer] > tx.Sequence, time.Since(tx.Time) > pendingTxTTL:
+			dropped++
+		default:
+			continue
+		}
+
+		if err = rs.RemovePendingTx(tx); err != nil {
+			return err
+		}
+	}
+
+	if committed > 0 || dropped > 0 {
+		c.Log(fmt.Sprintf("- [%s] reconciled relay state: %d pending txs committed, %d dropped, %d still pending",
+			c.ChainID, committed, dropped, len(txs)-committed-dropped))
+	}
+	return nil
+}
+
+func handshakeKey(kind, srcChainID, srcID, dstChainID, dstID string) []byte {
+	return []byte(fmt.Sprintf("handshake/%s/%s/%s/%s/%s", kind, srcChainID, srcID, dstChainID, dstID))
+}
+
+// SetHandshake records the progress of a handshake
+func (rs *RelayStore) SetHandshake(h Handshake) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	bz, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	return rs.db.SetSync(handshakeKey(h.Kind, h.SrcChainID, h.SrcID, h.DstChainID, h.DstID), bz)
+}
+
+// Handshake returns the last recorded progress of a handshake, if there is one
+func (rs *RelayStore) Handshake(kind, srcChainID, srcID, dstChainID, dstID string) (Handshake, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	var h Handshake
+	bz, err := rs.db.Get(handshakeKey(kind, srcChainID, srcID, dstChainID, dstID))
+	if err != nil || bz == nil || json.Unmarshal(bz, &h) != nil {
+		return h, false
+	}
+	return h, true
+}
+
+// UseRelayStore sets the store that the chain records the txs it broadcasts in
+func (src *Chain) UseRelayStore(rs *RelayStore) {
+	src.store = rs
+}
+
+// trackTx records the tx in the chain's relay store, if it has one, before it is
+// broadcast and returns a function that updates the store with the broadcast result
+func (src *Chain) trackTx(txBytes []byte, signer sdk.AccAddress, sequence uint64, msgs []sdk.Msg) func(sdk.TxResponse, error) {
+	if src.store == nil {
+		return func(sdk.TxResponse, error) {}
+	}
+
+	tx := PendingTx{
+		ChainID:  src.ChainID,
+		TxHash:   txHash(txBytes),
+		Signer:   signer.String(),
+		Sequence: sequence,
+		Time:     time.Now(),
+	}
+	for _, msg := range msgs {
+		if packet, ok := msgPacket(msg); ok {
+			tx.Packets = append(tx.Packets, PendingPacket{
+				MsgType:   msg.Type(),
+				PortID:    packet.GetSourcePort(),
+				ChannelID: packet.GetSourceChannel(),
+				Sequence:  packet.GetSequence(),
+			})
+		}
+	}
+
+	if err := src.store.AddPendingTx(tx); err != nil {
+		src.Error(fmt.Errorf("failed to record tx %s: %w", tx.TxHash, err))
+		return func(sdk.TxResponse, error) {}
+	}
+
+	return func(res sdk.TxResponse, err error) {
+		switch {
+		case err != nil:
+			// the tx may or may not have reached the mempool, leave it for Reconcile
+			return
+		case res.Code == 0 && res.Height == 0:
+			// accepted into the mempool but not yet committed
+			return
+		}
+
+		// the tx was either rejected or committed
+		if err = src.store.RemovePendingTx(tx); err != nil {
+			src.Error(fmt.Errorf("failed to update tx %s: %w", tx.TxHash, err))
+		}
+	}
+}
+
+// pendingPacket returns true if a pending tx on the chain relays the packet sent from pe with seq
+func (src *Chain) pendingPacket(pe *PathEnd, seq uint64) bool {
+	return src.store != nil && src.store.PendingPacket(src.ChainID, pe.PortID, pe.ChannelID, seq)
+}
+
+// queryAccountSequence returns the current sequence of the account with the bech32 address
+func (src *Chain) queryAccountSequence(address string) (uint64, error) {
+	done := src.UseSDKContext()
+	defer done()
+
+	addr, err := sdk.AccAddressFromBech32(address)
+	if err != nil {
+		return 0, err
+	}
+
+	_, sequence, err := queryAccount(src, addr)
+	return sequence, err
+}
+
+// reconcileRelayStores reconciles the relay stores of src and dst with their chains
+func reconcileRelayStores(src, dst *Chain) {
+	for _, c := range []*Chain{src, dst} {
+		if c.store == nil {
+			continue
+		}
+		if err := c.store.Reconcile(c); err != nil {
+			c.Error(fmt.Errorf("failed to reconcile relay state: %w", err))
+		}
+	}
+}
+
+// withoutPendingSequences removes the sequences of packets that have relay txs pending on either chain
+func withoutPendingSequences(src, dst *Chain, sp *RelaySequences) *RelaySequences {
+	return &RelaySequences{
+		Src: withoutPending(src, dst, src.PathEnd, sp.Src),
+		Dst: withoutPending(dst, src, dst.PathEnd, sp.Dst),
+	}
+}
+
+// withoutPending returns the seqs of packets sent from pe on c that have no relay tx
+// pending on either c or its counterparty
+func withoutPending(c, counterparty *Chain, pe *PathEnd, seqs []uint64) []uint64 {
+	out := make([]uint64, 0, len(seqs))
+	for _, seq := range seqs {
+		if c.pendingPacket(pe, seq) || counterparty.pendingPacket(pe, seq) {
+			c.Log(fmt.Sprintf("- [%s] packet seq(%d) has a pending relay tx, not relaying", c.ChainID, seq))
+			continue
+		}
+		out = append(out, seq)
+	}
+	return out
+}
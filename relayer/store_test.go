@@ -0,0 +1,32 @@
+package relayer
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenRelayStorePerPath(t *testing.T) {
+	home, err := ioutil.TempDir("", "relayer")
+	require.NoError(t, err)
+	defer os.RemoveAll(home)
+
+	a, err := OpenRelayStore(home, "a")
+	require.NoError(t, err)
+	defer a.Close()
+
+	// another path's store can be opened while a is held, e.g. by another rly start
+	b, err := OpenRelayStore(home, "b")
+	require.NoError(t, err)
+	defer b.Close()
+
+	_, err = OpenRelayStore(home, "a")
+	require.Error(t, err)
+
+	tx := PendingTx{ChainID: "ibc0", TxHash: "AB", Packets: []PendingPacket{{PortID: "transfer", ChannelID: "ch", Sequence: 1}}}
+	require.NoError(t, a.AddPendingTx(tx))
+	require.True(t, a.PendingPacket("ibc0", "transfer", "ch", 1))
+	require.False(t, b.PendingPacket("ibc0", "transfer", "ch", 1))
+}
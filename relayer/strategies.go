@@ -1,10 +1,10 @@
 package relayer
 
 import (
-	"context"
 	"fmt"
-
-	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"sort"
+	"sync"
+	"time"
 )
 
 var (
@@ -20,6 +20,54 @@ type Strategy interface {
 	UnrelayedSequencesOrdered(src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error)
 	RelayPacketsOrderedChan(src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error
 	RelayPacketsUnorderedChan(src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error
+	UnrelayedAcknowledgements(src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error)
+	RelayAcknowledgements(src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error
+}
+
+// StrategyConstructor returns a new instance of a strategy with its default settings
+type StrategyConstructor func() Strategy
+
+// StrategyOptionsParser sets the strategy specific fields on a strategy returned
+// by its StrategyConstructor. Keys that the strategy doesn't know about should be ignored.
+type StrategyOptionsParser func(strategy Strategy, opts map[string]string) error
+
+type strategyRegistration struct {
+	constructor StrategyConstructor
+	parser      StrategyOptionsParser
+}
+
+var (
+	strategiesMu sync.RWMutex
+	strategies   = make(map[string]strategyRegistration)
+)
+
+// RegisterStrategy makes a strategy available to paths under the given name.
+// The parser may be nil if the strategy takes no options.
+// It panics if the name is empty or already registered.
+func RegisterStrategy(name string, constructor StrategyConstructor, parser StrategyOptionsParser) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+
+	if name == "" || constructor == nil {
+		panic("relayer: strategy registration requires a name and a constructor")
+	}
+	if _, found := strategies[name]; found {
+		panic(fmt.Sprintf("relayer: strategy %s registered twice", name))
+	}
+	strategies[name] = strategyRegistration{constructor: constructor, parser: parser}
+}
+
+// RegisteredStrategies returns the sorted names of all the registered strategies
+func RegisteredStrategies() []string {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+
+	out := make([]string, 0, len(strategies))
+	for name := range strategies {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
 }
 
 // MustGetStrategy returns the strategy and panics on error
@@ -33,21 +81,100 @@ func (r *Path) MustGetStrategy() Strategy {
 
 // GetStrategy the strategy defined in the relay messages
 func (r *Path) GetStrategy() (Strategy, error) {
-	switch r.Strategy.Type {
-	case (&NaiveStrategy{}).GetType():
-		return &NaiveStrategy{}, nil
-	default:
-		return nil, fmt.Errorf("invalid strategy: %s", r.Strategy.Type)
+	if r.Strategy == nil {
+		return nil, fmt.Errorf("no strategy configured for path")
 	}
+	return r.Strategy.GetStrategy()
 }
 
-// StrategyCfg defines which relaying strategy to take for a given path
+// StrategyCfg defines which relaying strategy to take for a given path.
+// Constraints holds strategy specific options (e.g. max-tx-size, max-msgs)
+// which are passed to the strategy's options parser.
 type StrategyCfg struct {
-	Type string `json:"type" yaml:"type"`
+	Type        string            `json:"type" yaml:"type"`
+	Constraints map[string]string `json:"constraints,omitempty" yaml:"constraints,omitempty"`
+
+	// SweepInterval is how often to relay any backlog left behind by event driven
+	// relaying, e.g. because of dropped events or failed transactions (e.g. "30s")
+	SweepInterval string `json:"sweep-interval,omitempty" yaml:"sweep-interval,omitempty"`
+
+	// Filter restricts which packets are relayed over the path
+	Filter *PacketFilter `json:"filter,omitempty" yaml:"filter,omitempty"`
+}
+
+// FilteredStrategy is implemented by strategies that support packet filters
+type FilteredStrategy interface {
+	SetPacketFilter(filter *PacketFilter)
+}
+
+// GetStrategy returns the registered strategy of the configured type with the
+// configured constraints applied
+func (cfg *StrategyCfg) GetStrategy() (Strategy, error) {
+	strategiesMu.RLock()
+	reg, ok := strategies[cfg.Type]
+	strategiesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("invalid strategy: %s, registered strategies: %v", cfg.Type, RegisteredStrategies())
+	}
+
+	strat := reg.constructor()
+	if reg.parser != nil && len(cfg.Constraints) > 0 {
+		if err := reg.parser(strat, cfg.Constraints); err != nil {
+			return nil, fmt.Errorf("invalid %s strategy options: %w", cfg.Type, err)
+		}
+	}
+
+	if cfg.Filter != nil {
+		fs, ok := strat.(FilteredStrategy)
+		if !ok {
+			return nil, fmt.Errorf("strategy %s does not support packet filters", cfg.Type)
+		}
+		if err := cfg.Filter.Validate(); err != nil {
+			return nil, err
+		}
+		fs.SetPacketFilter(cfg.Filter)
+	}
+	return strat, nil
+}
+
+// WithOptions returns a copy of the StrategyCfg with opts layered over the
+// configured constraints
+func (cfg *StrategyCfg) WithOptions(opts map[string]string) *StrategyCfg {
+	out := &StrategyCfg{Type: cfg.Type, SweepInterval: cfg.SweepInterval, Filter: cfg.Filter, Constraints: make(map[string]string, len(cfg.Constraints)+len(opts))}
+	for k, v := range cfg.Constraints {
+		out.Constraints[k] = v
+	}
+	for k, v := range opts {
+		out.Constraints[k] = v
+	}
+	return out
+}
+
+// GetSweepInterval returns the parsed sweep interval, zero means the backlog is never swept
+func (cfg *StrategyCfg) GetSweepInterval() (time.Duration, error) {
+	if cfg == nil || cfg.SweepInterval == "" {
+		return 0, nil
+	}
+
+	interval, err := time.ParseDuration(cfg.SweepInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sweep-interval %s: %w", cfg.SweepInterval, err)
+	}
+	if interval < 0 {
+		return 0, fmt.Errorf("sweep-interval must not be negative, got %s", cfg.SweepInterval)
+	}
+	return interval, nil
 }
 
 // RunStrategy runs a given strategy
 func RunStrategy(src, dst *Chain, strategy Strategy, ordered bool) (func(), error) {
+	return RunStrategyWithSweep(src, dst, strategy, ordered, 0)
+}
+
+// RunStrategyWithSweep runs a given strategy and, if sweepInterval is positive, also
+// relays any backlog between src and dst every sweepInterval to catch packets that
+// event driven relaying missed
+func RunStrategyWithSweep(src, dst *Chain, strategy Strategy, ordered bool, sweepInterval time.Duration) (func(), error) {
 	doneChan := make(chan struct{})
 
 	// Fetch latest headers for each chain and store them in sync headers
@@ -57,10 +184,52 @@ func RunStrategy(src, dst *Chain, strategy Strategy, ordered bool) (func(), erro
 	}
 
 	// Next start the goroutine that listens to each chain for block and tx events
-	go relayerListenLoop(src, dst, doneChan, sh, strategy)
+	go relayerListenLoop(src, dst, doneChan, sh, strategy, ordered)
+
+	// Relay any packets and acknowledgements that remain to be relayed
+	if err = relayBacklog(src, dst, strategy, ordered, sh); err != nil {
+		return nil, err
+	}
+
+	// Periodically sweep for anything left over
+	sweepDone := make(chan struct{})
+	if sweepInterval > 0 {
+		go sweepBacklog(src, dst, strategy, ordered, sh, sweepInterval, sweepDone)
+	}
 
+	// Return a function to stop the relayer goroutines
+	return func() {
+		close(sweepDone)
+		doneChan <- struct{}{}
+	}, nil
+}
+
+// sweepBacklog relays the backlog between src and dst every interval until doneChan is closed
+func sweepBacklog(src, dst *Chain, strategy Strategy, ordered bool, sh *SyncHeaders,
+	interval time.Duration, doneChan <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := relayBacklog(src, dst, strategy, ordered, sh); err != nil {
+				src.Error(err)
+			}
+		case <-doneChan:
+			return
+		}
+	}
+}
+
+// relayBacklog relays any packets and acknowledgements between src and dst that
+// remain to be relayed, e.g. because they were sent while the relayer wasn't running
+func relayBacklog(src, dst *Chain, strategy Strategy, ordered bool, sh *SyncHeaders) error {
 	// Fetch any unrelayed sequences depending on the channel order
-	var sp *RelaySequences
+	var (
+		sp  *RelaySequences
+		err error
+	)
 	if ordered {
 		sp, err = strategy.UnrelayedSequencesOrdered(src, dst, sh)
 	} else {
@@ -68,9 +237,13 @@ func RunStrategy(src, dst *Chain, strategy Strategy, ordered bool) (func(), erro
 	}
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
+	// Leave out packets with relay txs that are still pending from before
+	reconcileRelayStores(src, dst)
+	sp = withoutPendingSequences(src, dst, sp)
+
 	// Relay any packets that remain to be relayed depending on order
 	if ordered {
 		err = strategy.RelayPacketsOrderedChan(src, dst, sp, sh)
@@ -79,89 +252,115 @@ func RunStrategy(src, dst *Chain, strategy Strategy, ordered bool) (func(), erro
 	}
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// Return a function to stop the relayer goroutine
-	return func() { doneChan <- struct{}{} }, nil
-}
-
-func relayerListenLoop(src, dst *Chain, doneChan chan struct{}, sh *SyncHeaders, strategy Strategy) {
-	var (
-		srcTxEvents, srcBlockEvents, dstTxEvents, dstBlockEvents <-chan ctypes.ResultEvent
-		srcTxCancel, srcBlockCancel, dstTxCancel, dstBlockCancel context.CancelFunc
-		err                                                      error
-	)
-
-	// Start client for source chain
-	if err = src.Start(); err != nil {
-		src.Error(err)
-		return
+	// Relay any acknowledgements written while the relayer wasn't running
+	ap, err := strategy.UnrelayedAcknowledgements(src, dst, sh)
+	if err != nil {
+		return err
 	}
+	ap = withoutPendingSequences(src, dst, ap)
 
-	// Subscibe to txEvents from the source chain
-	if srcTxEvents, srcTxCancel, err = src.Subscribe(txEvents); err != nil {
-		src.Error(err)
-		return
-	}
-	defer srcTxCancel()
-	src.Log(fmt.Sprintf("- listening to tx events from %s...", src.ChainID))
+	return strategy.RelayAcknowledgements(src, dst, ap, sh)
+}
 
-	// Subscibe to blockEvents from the source chain
-	if srcBlockEvents, srcBlockCancel, err = src.Subscribe(blEvents); err != nil {
+// relayerListenLoop handles the tx and block events of src and dst until a value is
+// received on doneChan. Subscriptions that are closed or stop delivering blocks are
+// reconnected, after which any packets missed in the meantime are relayed.
+func relayerListenLoop(src, dst *Chain, doneChan chan struct{}, sh *SyncHeaders, strategy Strategy, ordered bool) {
+	srcSub, err := subscribeEvents(src)
+	if err != nil {
 		src.Error(err)
 		return
 	}
-	defer srcBlockCancel()
-	src.Log(fmt.Sprintf("- listening to block events from %s...", src.ChainID))
+	defer func() { srcSub.cancel() }()
 
-	// Subscribe to destination chain
-	if err = dst.Start(); err != nil {
+	dstSub, err := subscribeEvents(dst)
+	if err != nil {
 		dst.Error(err)
 		return
 	}
+	defer func() { dstSub.cancel() }()
 
-	// Subscibe to txEvents from the destination chain
-	if dstTxEvents, dstTxCancel, err = dst.Subscribe(txEvents); err != nil {
-		dst.Error(err)
-		return
+	shutdown := func() {
+		src.Log(fmt.Sprintf("- [%s]:{%s} <-> [%s]:{%s} relayer shutting down",
+			src.ChainID, src.PathEnd.PortID, dst.ChainID, dst.PathEnd.PortID))
+		close(doneChan)
 	}
-	defer dstTxCancel()
-	dst.Log(fmt.Sprintf("- listening to tx events from %s...", dst.ChainID))
 
-	// Subscibe to blockEvents from the destination chain
-	if dstBlockEvents, dstBlockCancel, err = dst.Subscribe(blEvents); err != nil {
-		src.Error(err)
-		return
+	// resubscribe restores a lost subscription and relays anything missed while it was down
+	resubscribe := func(sub *eventSubscription) bool {
+		if !sub.resubscribe(doneChan) {
+			return false
+		}
+		if err := sh.Update(sub.chain); err != nil {
+			sub.chain.Error(err)
+		}
+		go func() {
+			if err := relayBacklog(src, dst, strategy, ordered, sh); err != nil {
+				src.Error(err)
+			}
+		}()
+		return true
 	}
-	defer dstBlockCancel()
-	dst.Log(fmt.Sprintf("- listening to block events from %s...", dst.ChainID))
+
+	ticker := time.NewTicker(staleSubscriptionTimeout / 2)
+	defer ticker.Stop()
 
 	// Listen to channels and take appropriate action
 	for {
+		var sub *eventSubscription
 		select {
-		case srcMsg := <-srcTxEvents:
+		case srcMsg, ok := <-srcSub.txs:
+			if !ok {
+				sub = srcSub
+				break
+			}
 			src.logTx(srcMsg.Events)
 			go strategy.HandleEvents(dst, src, sh, srcMsg.Events)
-		case dstMsg := <-dstTxEvents:
+		case dstMsg, ok := <-dstSub.txs:
+			if !ok {
+				sub = dstSub
+				break
+			}
 			dst.logTx(dstMsg.Events)
 			go strategy.HandleEvents(src, dst, sh, dstMsg.Events)
-		case srcMsg := <-srcBlockEvents:
+		case srcMsg, ok := <-srcSub.blocks:
+			if !ok {
+				sub = srcSub
+				break
+			}
 			// TODO: Add debug block logging here
+			srcSub.lastBlock = time.Now()
 			if err = sh.Update(src); err != nil {
 				src.Error(err)
 			}
 			go strategy.HandleEvents(dst, src, sh, srcMsg.Events)
-		case dstMsg := <-dstBlockEvents:
+		case dstMsg, ok := <-dstSub.blocks:
+			if !ok {
+				sub = dstSub
+				break
+			}
 			// TODO: Add debug block logging here
+			dstSub.lastBlock = time.Now()
 			if err = sh.Update(dst); err != nil {
 				dst.Error(err)
 			}
 			go strategy.HandleEvents(src, dst, sh, dstMsg.Events)
+		case <-ticker.C:
+			if srcSub.stale() {
+				sub = srcSub
+			} else if dstSub.stale() {
+				sub = dstSub
+			}
 		case <-doneChan:
-			src.Log(fmt.Sprintf("- [%s]:{%s} <-> [%s]:{%s} relayer shutting down",
-				src.ChainID, src.PathEnd.PortID, dst.ChainID, dst.PathEnd.PortID))
-			close(doneChan)
+			shutdown()
+			return
+		}
+
+		if sub != nil && !resubscribe(sub) {
+			shutdown()
 			return
 		}
 	}
@@ -0,0 +1,96 @@
+package relayer
+
+import (
+	"fmt"
+	"time"
+
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+var (
+	// staleSubscriptionTimeout is how long a subscription can go without a block
+	// event before the relayer reconnects to the chain
+	staleSubscriptionTimeout = time.Minute
+
+	// minReconnectBackoff and maxReconnectBackoff bound the wait between attempts
+	// to reconnect to a chain
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = time.Minute
+)
+
+// eventSubscription holds a chain's subscriptions to tx and block events
+type eventSubscription struct {
+	chain     *Chain
+	txs       <-chan ctypes.ResultEvent
+	blocks    <-chan ctypes.ResultEvent
+	cancel    func()
+	lastBlock time.Time
+}
+
+// subscribeEvents starts the chain's client if needed and subscribes to its tx and block events
+func subscribeEvents(c *Chain) (*eventSubscription, error) {
+	if !c.RPCClient().IsRunning() {
+		if err := c.Start(); err != nil {
+			return nil, err
+		}
+	}
+
+	txs, txCancel, err := c.Subscribe(txEvents)
+	if err != nil {
+		return nil, err
+	}
+	c.Log(fmt.Sprintf("- listening to tx events from %s...", c.ChainID))
+
+	blocks, blockCancel, err := c.Subscribe(blEvents)
+	if err != nil {
+		txCancel()
+		return nil, err
+	}
+	c.Log(fmt.Sprintf("- listening to block events from %s...", c.ChainID))
+
+	return &eventSubscription{
+		chain:     c,
+		txs:       txs,
+		blocks:    blocks,
+		cancel:    func() { txCancel(); blockCancel() },
+		lastBlock: time.Now(),
+	}, nil
+}
+
+// stale returns true if the chain's client has stopped or no block
+// event has been received within the staleSubscriptionTimeout
+func (es *eventSubscription) stale() bool {
+	return !es.chain.RPCClient().IsRunning() || time.Since(es.lastBlock) > staleSubscriptionTimeout
+}
+
+// resubscribe reconnects to the chain and subscribes to its events again, backing
+// off exponentially between failed attempts. It returns false if a value is received
+// on doneChan before the subscription is restored.
+func (es *eventSubscription) resubscribe(doneChan chan struct{}) bool {
+	es.cancel()
+
+	backoff := minReconnectBackoff
+	for {
+		es.chain.Log(fmt.Sprintf("- [%s] event subscription lost, reconnecting...", es.chain.ChainID))
+
+		err := es.chain.Reconnect()
+		if err == nil {
+			var sub *eventSubscription
+			if sub, err = subscribeEvents(es.chain); err == nil {
+				*es = *sub
+				return true
+			}
+		}
+		es.chain.Error(fmt.Errorf("failed to reconnect, retrying in %s: %w", backoff, err))
+
+		select {
+		case <-time.After(backoff):
+		case <-doneChan:
+			return false
+		}
+
+		if backoff *= 2; backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
+	}
+}